@@ -6,14 +6,25 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/url"
+	"time"
+	_ "time/tzdata"
 
 	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/mymmrac/telego"
 
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/callback"
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/config"
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/i18n"
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/models"
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/pickuptime"
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/pricing"
 	"github.com/andrey-berenda/perfect-driver/internal/pkg/storage"
 )
 
 type Handler struct {
+	cfg       *config.Config
+	callbacks *callback.Codec
+	store     *storage.Store
 }
 
 type OrderData struct {
@@ -22,6 +33,7 @@ type OrderData struct {
 	Time        string
 	Phone       string
 	Name        string
+	Experience  string
 }
 
 func Parse(payload string) (OrderData, error) {
@@ -35,6 +47,7 @@ func Parse(payload string) (OrderData, error) {
 	}
 	return OrderData{
 		Name:        u.Get("Name"),
+		Experience:  u.Get("Experience"),
 		Phone:       u.Get("Phone"),
 		Time:        u.Get("Time"),
 		Source:      u.Get("Source"),
@@ -57,49 +70,103 @@ func (h Handler) Invoke(ctx context.Context, payload []byte) ([]byte, error) {
 	if err != nil {
 		return nil, fmt.Errorf("parse: %w", err)
 	}
-	bot, err := telego.NewBot("6281856678:AAGQdSTnZwoU5SPjXsa8IKVVnbZmriqS-0c")
+	bot, err := telego.NewBot(h.cfg.Telegram.DriverBotToken)
 	if err != nil {
 		return nil, err
 	}
 
+	store := h.store
 	if o.Name != "" {
-		_, err = bot.SendMessage(&telego.SendMessageParams{
-			ChatID: telego.ChatID{ID: -908250161},
-			Text: fmt.Sprintf(`Новая заявка от водителя
-Телефон: %s
-Имя: %s
-`, o.Phone, o.Name),
-		})
+		driver, err := store.DriverCreate(ctx, o.Name, o.Phone, o.Experience)
+		if err != nil {
+			return nil, err
+		}
+		err = sendDriverApplication(driver, bot, h.callbacks, h.cfg.Telegram.AdminChatID)
 		return nil, err
 	}
-	store := storage.New(nil)
-	order, err := store.OrderCreateFromLambda(ctx, o.Source, o.Destination, o.Time, o.Phone)
+	location, err := h.cfg.Location()
 	if err != nil {
 		return nil, err
 	}
-	err = sendMessage(order.ToDriverChat(), order.ID, bot)
+	// The site form has no way to ask the customer again, so a time that
+	// cannot be parsed is passed to drivers as typed.
+	quoted := &models.Order{}
+	scheduledAt, err := pickuptime.Parse(o.Time, time.Now(), location)
+	if err == nil {
+		quoted.ScheduledAt = &scheduledAt
+	}
+	// The order is quoted before it is created: once inserted, it is
+	// awaiting a driver and must already have a price.
+	patch, err := pricing.New(store, location).Quote(ctx, quoted, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	patch.Source = &o.Source
+	patch.Destination = &o.Destination
+	patch.Time = &o.Time
+	patch.Phone = &o.Phone
+	patch.ScheduledAt = quoted.ScheduledAt
+	order, err := store.OrderCreateFromLambda(ctx, patch)
+	if err != nil {
+		return nil, err
+	}
+	post, err := sendMessage(order.ToDriverChat(chatPrinter, location), order.ID, bot, h.callbacks, h.cfg.Telegram.DriversChatID)
+	if err != nil {
+		return nil, err
+	}
+	_, err = store.OrderUpdate(ctx, order.ID, storage.OrderPatch{DriversChatMessageID: &post.MessageID})
 	return nil, err
 }
 
-func sendMessage(text string, orderID int, bot *telego.Bot) error {
-	_, err := bot.SendMessage(&telego.SendMessageParams{
-		ChatID: telego.ChatID{ID: -1001520856813},
+// chatPrinter is used for the drivers and admin chats.
+var chatPrinter = i18n.New(i18n.Default)
+
+func sendMessage(
+	text string,
+	orderID int,
+	bot *telego.Bot,
+	callbacks *callback.Codec,
+	chatID int64,
+) (*telego.Message, error) {
+	return bot.SendMessage(&telego.SendMessageParams{
+		ChatID: telego.ChatID{ID: chatID},
 		Text:   text,
 		ReplyMarkup: &telego.InlineKeyboardMarkup{
 			InlineKeyboard: [][]telego.InlineKeyboardButton{
 				{
-					{Text: "Возьму заказ", CallbackData: fmt.Sprintf("%s:%d", "order", orderID)},
+					{Text: chatPrinter.T(i18n.ButtonTakeOrder), CallbackData: callbacks.Encode(callback.TakeOrder{OrderID: orderID})},
 				},
 			},
 		},
 	})
-	if err != nil {
-		return err
-	}
-	return nil
+}
+
+func sendDriverApplication(driver *models.Driver, bot *telego.Bot, callbacks *callback.Codec, chatID int64) error {
+	_, err := bot.SendMessage(&telego.SendMessageParams{
+		ChatID: telego.ChatID{ID: chatID},
+		Text:   driver.ToAdminChat(chatPrinter),
+		ReplyMarkup: &telego.InlineKeyboardMarkup{
+			InlineKeyboard: [][]telego.InlineKeyboardButton{
+				{
+					{Text: chatPrinter.T(i18n.ButtonApprove), CallbackData: callbacks.Encode(callback.ApproveDriver{DriverID: driver.ID})},
+					{Text: chatPrinter.T(i18n.ButtonReject), CallbackData: callbacks.Encode(callback.RejectDriver{DriverID: driver.ID})},
+				},
+			},
+		},
+	})
+	return err
 }
 
 func main() {
-	var h lambda.Handler = Handler{}
+	cfg, err := config.LoadLambda()
+	if err != nil {
+		panic(fmt.Sprintf("config.LoadLambda: %s", err))
+	}
+	// The pool outlives a single invocation: Lambda reuses the process while it is warm.
+	store, err := storage.New(context.Background(), cfg.Database, nil)
+	if err != nil {
+		panic(fmt.Sprintf("storage.New: %s", err))
+	}
+	var h lambda.Handler = Handler{cfg: cfg, callbacks: callback.New(cfg.Telegram.CallbackSecret), store: store}
 	lambda.Start(h)
 }
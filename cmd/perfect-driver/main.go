@@ -2,19 +2,20 @@ package main
 
 import (
 	"context"
-	"encoding/base64"
-	"fmt"
 	"net/http"
 	"os"
 	"os/signal"
 	"sync"
 	"time"
+	_ "time/tzdata"
 
-	"github.com/google/uuid"
 	"github.com/mymmrac/telego"
 
 	"github.com/andrey-berenda/perfect-driver/internal/pkg/bot"
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/config"
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/dispatch"
 	"github.com/andrey-berenda/perfect-driver/internal/pkg/log"
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/pricing"
 	"github.com/andrey-berenda/perfect-driver/internal/pkg/processing"
 	"github.com/andrey-berenda/perfect-driver/internal/pkg/storage"
 )
@@ -23,85 +24,79 @@ func main() {
 	time.Local = time.UTC
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
 
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		err := runMigrate(ctx, os.Args[2:])
+		cancel()
+		exitOnError(err)
+		return
+	}
+
 	logger := log.NewLogger()
-	driverBot, err := telego.NewBot("6281856678:AAGQdSTnZwoU5SPjXsa8IKVVnbZmriqS-0c")
+	cfg, err := config.Load()
+	if err != nil {
+		logger.Fatalf("config.Load: %v", err)
+	}
+	location, err := cfg.Location()
+	if err != nil {
+		logger.Fatalf("cfg.Location: %v", err)
+	}
+	if err = checkSchema(ctx, cfg.Database); err != nil {
+		logger.Fatalf("checkSchema: %v", err)
+	}
+	driverBot, err := telego.NewBot(cfg.Telegram.DriverBotToken)
 	if err != nil {
 		logger.Fatalf("telego.NewBot: %v", err)
 	}
-	customerBot, err := telego.NewBot("6196016370:AAHuOP1C69M2hh9Z8DfhY9EImZgCUSq-OvY")
+	customerBot, err := telego.NewBot(cfg.Telegram.CustomerBotToken)
 	if err != nil {
 		logger.Fatalf("telego.NewBot: %v", err)
 	}
-	store := storage.New(logger)
+	store, err := storage.New(ctx, cfg.Database, logger)
+	if err != nil {
+		logger.Fatalf("storage.New: %v", err)
+	}
 
-	yookassaPaymentsURL := "https://api.yookassa.ru/v3/payments"
+	processor := processing.New(store, http.DefaultClient, cfg.YooKassa, cfg.Cancellation)
 
-	processor := processing.New(
-		store,
-		http.DefaultClient,
-		base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf(
-			"%d:%s",
-			974352,
-			"test_IFouxng-0oLRTgFPHEVMB5knuEPEvPL5siwYcSKf3pA",
-		))),
-		yookassaPaymentsURL,
-		func(orderID uuid.UUID) string {
-			return fmt.Sprintf("%s/%s", yookassaPaymentsURL, orderID.String())
-		},
-	)
+	pricer := pricing.New(store, location)
 
-	b := bot.New(driverBot, customerBot, processor, store, logger, -1001520856813)
+	b := bot.New(driverBot, customerBot, processor, pricer, store, logger, cfg.Telegram, cfg.Commission, location)
+	u := &updates{cfg: cfg.Telegram, driverBot: driverBot, customerBot: customerBot, logger: logger}
+	driverUpdates, customerUpdates, err := u.Start(cancel)
+	if err != nil {
+		logger.Fatalf("updates.Start: %v", err)
+	}
+	var metrics *http.Server
+	if cfg.Updates.MetricsAddress != "" {
+		metrics = serveMetrics(cfg.Updates.MetricsAddress, logger)
+	}
+	driverDispatcher := dispatch.New("driver", cfg.Updates.Workers, cfg.Updates.QueueSize, b.HandleDriverUpdate)
+	customerDispatcher := dispatch.New("customer", cfg.Updates.Workers, cfg.Updates.QueueSize, b.HandleCustomerUpdate)
 	wg := &sync.WaitGroup{}
 
 	wg.Add(1)
 	go func() {
-		updates, err := driverBot.UpdatesViaLongPolling(&telego.GetUpdatesParams{
-			Timeout: 10,
-			AllowedUpdates: []string{
-				telego.MessageUpdates,
-				telego.CallbackQueryUpdates,
-				telego.ChannelPostUpdates,
-			},
-		})
-		if err != nil {
-			cancel()
-			logger.Errorf("driverBot.UpdatesViaLongPolling: %v", err)
-			return
-		}
 		logger.Info("Starting handle driver messages")
-		b.HandleCustomerUpdates(updates)
+		driverDispatcher.Run(driverUpdates)
 		logger.Info("Handling messages driver stopped")
 		wg.Done()
-
 	}()
 
 	wg.Add(1)
 	go func() {
-		updates, err := customerBot.UpdatesViaLongPolling(&telego.GetUpdatesParams{
-			Timeout: 10,
-			AllowedUpdates: []string{
-				telego.MessageUpdates,
-				telego.CallbackQueryUpdates,
-				telego.ChannelPostUpdates,
-			},
-		})
-		if err != nil {
-			cancel()
-			logger.Errorf("driverBot.UpdatesViaLongPolling: %v", err)
-			return
-		}
-
 		logger.Info("Starting handle customer messages")
-		b.HandleCustomerUpdates(updates)
+		customerDispatcher.Run(customerUpdates)
 		logger.Info("Handling messages customer stopped")
 		wg.Done()
 	}()
 
 	<-ctx.Done()
 
-	driverBot.StopLongPolling()
-	customerBot.StopLongPolling()
+	u.Stop()
 	wg.Wait()
+	if metrics != nil {
+		_ = metrics.Close()
+	}
 	logger.Info("Bot gracefully stopped")
 	_ = logger.Sync()
 }
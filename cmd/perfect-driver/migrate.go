@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/config"
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/migrate"
+	"github.com/andrey-berenda/perfect-driver/migrations"
+)
+
+const migrateUsage = `usage: perfect-driver migrate up|down|status|create <name> [-dir migrations]`
+
+func runMigrate(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+	command := args[0]
+	flags := flag.NewFlagSet("migrate "+command, flag.ContinueOnError)
+	dir := flags.String("dir", "migrations", "directory to create migrations in")
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	if command == "create" {
+		if flags.NArg() != 1 {
+			return errors.New(migrateUsage)
+		}
+		filename, err := migrate.Create(*dir, flags.Arg(0), time.Now())
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Created %s\n", filename)
+		return nil
+	}
+
+	cfg, err := config.LoadDatabase()
+	if err != nil {
+		return err
+	}
+	conn, err := pgx.Connect(ctx, cfg.URL)
+	if err != nil {
+		return fmt.Errorf("pgx.Connect: %w", err)
+	}
+	defer conn.Close(context.Background())
+
+	migrator, err := migrate.New(conn, migrations.FS)
+	if err != nil {
+		return err
+	}
+
+	switch command {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, m := range applied {
+			fmt.Printf("OK   %s\n", m.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			fmt.Println("No migrations to apply")
+		}
+	case "down":
+		m, err := migrator.Down(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("OK   %s\n", m.Name)
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Println("    Applied At                  Migration")
+		fmt.Println("    =======================================")
+		for _, s := range statuses {
+			appliedAt := "Pending"
+			if s.AppliedAt != nil {
+				appliedAt = s.AppliedAt.Format(time.ANSIC)
+			}
+			fmt.Printf("    %-24s -- %s\n", appliedAt, s.Migration.Name)
+		}
+	default:
+		return errors.New(migrateUsage)
+	}
+	return nil
+}
+
+func checkSchema(ctx context.Context, cfg config.Database) error {
+	conn, err := pgx.Connect(ctx, cfg.URL)
+	if err != nil {
+		return fmt.Errorf("pgx.Connect: %w", err)
+	}
+	defer conn.Close(context.Background())
+
+	migrator, err := migrate.New(conn, migrations.FS)
+	if err != nil {
+		return err
+	}
+	return migrator.Check(ctx)
+}
+
+func exitOnError(err error) {
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mymmrac/telego"
+	"go.uber.org/zap"
+
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/config"
+)
+
+const (
+	driverWebhookPath   = "/telegram/driver"
+	customerWebhookPath = "/telegram/customer"
+)
+
+var allowedUpdates = []string{
+	telego.MessageUpdates,
+	telego.CallbackQueryUpdates,
+	telego.ChannelPostUpdates,
+}
+
+// updates receives updates of both bots either via long polling or via
+// webhooks served by a single HTTP server.
+type updates struct {
+	cfg         config.Telegram
+	driverBot   *telego.Bot
+	customerBot *telego.Bot
+	logger      *zap.SugaredLogger
+}
+
+func (u *updates) Start(cancel context.CancelFunc) (<-chan telego.Update, <-chan telego.Update, error) {
+	if u.cfg.Mode == config.ModeWebhook {
+		return u.startWebhook(cancel)
+	}
+	return u.startPolling()
+}
+
+func (u *updates) startPolling() (<-chan telego.Update, <-chan telego.Update, error) {
+	params := &telego.GetUpdatesParams{Timeout: 10, AllowedUpdates: allowedUpdates}
+	driverUpdates, err := u.driverBot.UpdatesViaLongPolling(params)
+	if err != nil {
+		return nil, nil, fmt.Errorf("driverBot.UpdatesViaLongPolling: %w", err)
+	}
+	customerUpdates, err := u.customerBot.UpdatesViaLongPolling(params)
+	if err != nil {
+		u.driverBot.StopLongPolling()
+		return nil, nil, fmt.Errorf("customerBot.UpdatesViaLongPolling: %w", err)
+	}
+	return driverUpdates, customerUpdates, nil
+}
+
+func (u *updates) startWebhook(cancel context.CancelFunc) (<-chan telego.Update, <-chan telego.Update, error) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	server := &telego.MultiBotWebhookServer{
+		Server: telego.HTTPWebhookServer{
+			Logger: u.driverBot.Logger(),
+			Server: &http.Server{
+				Addr:              u.cfg.Webhook.Address,
+				ReadHeaderTimeout: 10 * time.Second,
+			},
+			ServeMux:    mux,
+			SecretToken: u.cfg.Webhook.SecretToken,
+		},
+	}
+
+	driverUpdates, err := u.driverBot.UpdatesViaWebhook(
+		driverWebhookPath,
+		telego.WithWebhookServer(server),
+		telego.WithWebhookSet(u.setWebhookParams(driverWebhookPath)),
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("driverBot.UpdatesViaWebhook: %w", err)
+	}
+	customerUpdates, err := u.customerBot.UpdatesViaWebhook(
+		customerWebhookPath,
+		telego.WithWebhookServer(server),
+		telego.WithWebhookSet(u.setWebhookParams(customerWebhookPath)),
+	)
+	if err != nil {
+		u.deleteWebhook(u.driverBot, "driverBot")
+		return nil, nil, fmt.Errorf("customerBot.UpdatesViaWebhook: %w", err)
+	}
+
+	// Both bots share the server, it is started only once. StartWebhook
+	// blocks until the server is stopped.
+	for name, bot := range map[string]*telego.Bot{"driverBot": u.driverBot, "customerBot": u.customerBot} {
+		name, bot := name, bot
+		go func() {
+			if err := bot.StartWebhook(u.cfg.Webhook.Address); err != nil {
+				u.logger.Errorf("%s.StartWebhook: %v", name, err)
+				cancel()
+			}
+		}()
+	}
+	u.logger.Infof("Listening for webhooks on %s", u.cfg.Webhook.Address)
+	return driverUpdates, customerUpdates, nil
+}
+
+func (u *updates) setWebhookParams(path string) *telego.SetWebhookParams {
+	return &telego.SetWebhookParams{
+		URL:            strings.TrimRight(u.cfg.Webhook.URL, "/") + path,
+		SecretToken:    u.cfg.Webhook.SecretToken,
+		AllowedUpdates: allowedUpdates,
+	}
+}
+
+// Stop stops receiving updates and closes the update channels. In webhook
+// mode the webhooks are deregistered first so Telegram keeps the updates
+// until the bot is back.
+func (u *updates) Stop() {
+	if u.cfg.Mode != config.ModeWebhook {
+		u.driverBot.StopLongPolling()
+		u.customerBot.StopLongPolling()
+		return
+	}
+	u.deleteWebhook(u.driverBot, "driverBot")
+	u.deleteWebhook(u.customerBot, "customerBot")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := u.driverBot.StopWebhookWithContext(ctx); err != nil {
+		u.logger.Errorf("driverBot.StopWebhook: %v", err)
+	}
+	if err := u.customerBot.StopWebhookWithContext(ctx); err != nil {
+		u.logger.Errorf("customerBot.StopWebhook: %v", err)
+	}
+}
+
+func (u *updates) deleteWebhook(bot *telego.Bot, name string) {
+	if err := bot.DeleteWebhook(&telego.DeleteWebhookParams{}); err != nil {
+		u.logger.Errorf("%s.DeleteWebhook: %v", name, err)
+	}
+}
+
+// serveMetrics serves expvar metrics on their own address, which is kept
+// private unlike the webhook server.
+func serveMetrics(address string, logger *zap.SugaredLogger) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/debug/vars", expvar.Handler())
+	server := &http.Server{Addr: address, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		err := server.ListenAndServe()
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Errorf("metrics ListenAndServe: %v", err)
+		}
+	}()
+	return server
+}
@@ -4,225 +4,347 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"strconv"
-	"strings"
+	"time"
 
 	"github.com/mymmrac/telego"
 	"github.com/mymmrac/telego/telegoutil"
 	"go.uber.org/zap"
 
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/callback"
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/config"
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/dialog"
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/i18n"
 	"github.com/andrey-berenda/perfect-driver/internal/pkg/log"
 	"github.com/andrey-berenda/perfect-driver/internal/pkg/models"
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/pricing"
 	"github.com/andrey-berenda/perfect-driver/internal/pkg/processing"
 	"github.com/andrey-berenda/perfect-driver/internal/pkg/storage"
 )
 
-const createOrderCallback = "createOrder"
-const arrivedCallback = "arrived"
-const finishedCallback = "finished"
-const orderCallback = "order"
+var orderStatusTitles = map[models.OrderStatus]i18n.Key{
+	models.OrderStatusDraft:          i18n.OrderStatusDraft,
+	models.OrderStatusAwaitingDriver: i18n.OrderStatusAwaiting,
+	models.OrderStatusAssigned:       i18n.OrderStatusAssigned,
+	models.OrderStatusDriverArrived:  i18n.OrderStatusArrived,
+	models.OrderStatusInProgress:     i18n.OrderStatusProgress,
+	models.OrderStatusFinished:       i18n.OrderStatusFinished,
+	models.OrderStatusCancelled:      i18n.OrderStatusCancelled,
+}
 
-type MessageHandler func(ctx context.Context, update telego.Update) bool
-type CallbackHandler func(ctx context.Context, callback telego.CallbackQuery)
+type MessageHandler func(ctx context.Context, update telego.Update) error
+type CallbackHandler func(ctx context.Context, cb telego.CallbackQuery, data callback.Data) error
 
 type Bot struct {
-	driverBot        *telego.Bot
-	customerBot      *telego.Bot
-	processor        processing.Processor
-	store            *storage.Store
-	driversChatID    int64
-	handlers         []MessageHandler
-	commandHandlers  map[string]MessageHandler
-	callbackHandlers map[string]CallbackHandler
-	logger           *zap.SugaredLogger
+	driverBot         *telego.Bot
+	customerBot       *telego.Bot
+	processor         processing.Processor
+	pricer            *pricing.Pricer
+	store             storage.Repository
+	driversChatID     int64
+	adminChatID       int64
+	location          *time.Location
+	commissionPercent int64
+	// chatPrinter is used for the drivers and admin chats.
+	chatPrinter           i18n.Printer
+	customerHandler       MessageHandler
+	commandHandlers       map[string]MessageHandler
+	driverHandler         MessageHandler
+	driverCommandHandlers map[string]MessageHandler
+	callbacks             *callback.Codec
+	callbackHandlers      map[callback.Action]CallbackHandler
+	orderForm             *dialog.Flow[*models.Order]
+	feedbackForm          *dialog.Flow[*models.Rating]
+	profileForm           *dialog.Flow[*models.User]
+	placeForm             *dialog.Flow[*models.Place]
+	logger                *zap.SugaredLogger
 }
 
 func New(
 	driverBot *telego.Bot,
 	customerBot *telego.Bot,
 	processor processing.Processor,
-	store *storage.Store,
+	pricer *pricing.Pricer,
+	store storage.Repository,
 	logger *zap.SugaredLogger,
-	driversChatID int64,
+	cfg config.Telegram,
+	commission config.Commission,
+	location *time.Location,
 ) *Bot {
 	b := &Bot{
-		driverBot:     driverBot,
-		customerBot:   customerBot,
-		processor:     processor,
-		store:         store,
-		logger:        logger,
-		driversChatID: driversChatID,
-	}
-	b.handlers = []MessageHandler{
-		b.HandleMessage,
-	}
+		driverBot:         driverBot,
+		customerBot:       customerBot,
+		processor:         processor,
+		pricer:            pricer,
+		store:             store,
+		logger:            logger,
+		driversChatID:     cfg.DriversChatID,
+		adminChatID:       cfg.AdminChatID,
+		location:          location,
+		commissionPercent: commission.Percent,
+		callbacks:         callback.New(cfg.CallbackSecret),
+		chatPrinter:       i18n.New(i18n.Default),
+	}
+	middlewares := []Middleware{
+		b.withLogger,
+		b.recoverPanic,
+		b.withTimeout,
+		b.withLanguage,
+	}
+	b.orderForm = b.newOrderForm()
+	b.feedbackForm = b.newFeedbackForm()
+	b.profileForm = b.newProfileForm()
+	b.placeForm = b.newPlaceForm()
+	b.customerHandler = chain(b.routeCustomer, middlewares...)
+	b.driverHandler = chain(b.routeDriver, middlewares...)
 	b.commandHandlers = map[string]MessageHandler{
-		"start": b.HandleStartCommand,
+		"start":    b.HandleStartCommand,
+		"cancel":   b.HandleCancelCommand,
+		"language": b.HandleLanguageCommand,
+		"orders":   b.HandleOrdersCommand,
+		"profile":  b.HandleProfileCommand,
+	}
+	b.driverCommandHandlers = map[string]MessageHandler{
+		"start":    b.HandleDriverStartCommand,
+		"language": b.HandleLanguageCommand,
+		"stats":    b.HandleStatsCommand,
 	}
-	b.callbackHandlers = map[string]CallbackHandler{
-		createOrderCallback: b.HandleCreateOrder,
-		orderCallback:       b.HandleGetOrder,
-		arrivedCallback:     b.HandleArrived,
-		finishedCallback:    b.HandleFinished,
+	b.callbackHandlers = map[callback.Action]CallbackHandler{
+		callback.ActionCreateOrder: onCallback(b.HandleCreateOrder),
+		callback.ActionTakeOrder:   onCallback(b.HandleGetOrder),
+		callback.ActionArrived:     onCallback(b.HandleArrived),
+		callback.ActionStarted:     onCallback(b.HandleStarted),
+		callback.ActionFinished:    onCallback(b.HandleFinished),
+		callback.ActionCancelOrder: onCallback(b.HandleCancelOrder),
+
+		callback.ActionEditOrder:    onCallback(b.HandleEditOrder),
+		callback.ActionConfirmOrder: onCallback(b.HandleConfirmOrder),
+		callback.ActionRateOrder:    onCallback(b.HandleRateOrder),
+
+		callback.ActionOrderHistory: onCallback(b.HandleOrderHistory),
+		callback.ActionRepeatOrder:  onCallback(b.HandleRepeatOrder),
+
+		callback.ActionEditProfile: onCallback(b.HandleEditProfile),
+		callback.ActionSavePlace:   onCallback(b.HandleSavePlace),
+		callback.ActionDeletePlace: onCallback(b.HandleDeletePlace),
+
+		callback.ActionApproveDriver: onCallback(b.HandleApproveDriver),
+		callback.ActionRejectDriver:  onCallback(b.HandleRejectDriver),
+
+		callback.ActionSetLanguage: onCallback(b.HandleSetLanguage),
 	}
 	return b
 }
 
-func (b *Bot) HandleCustomerUpdates(updates <-chan telego.Update) {
-	for update := range updates {
-		ctx := context.Background()
-		for _, handler := range b.handlers {
-			if handler(ctx, update) {
-				break
-			}
-		}
+// HandleCustomerUpdate handles an update of the customer bot. Errors are
+// logged by the middlewares.
+func (b *Bot) HandleCustomerUpdate(ctx context.Context, update telego.Update) {
+	_ = b.customerHandler(ctx, update)
+}
+
+func (b *Bot) HandleDriverUpdate(ctx context.Context, update telego.Update) {
+	_ = b.driverHandler(ctx, update)
+}
+
+func (b *Bot) routeCustomer(ctx context.Context, update telego.Update) error {
+	ctx = withReplyBot(ctx, b.customerBot)
+	if update.CallbackQuery != nil {
+		return b.handleCallback(ctx, *update.CallbackQuery)
 	}
+	if update.Message == nil {
+		return nil
+	}
+	return b.HandleMessage(ctx, update)
 }
 
-func (b *Bot) HandleDriverUpdates(updates <-chan telego.Update) {
-	for update := range updates {
-		ctx := context.Background()
-		for _, handler := range b.handlers {
-			if handler(ctx, update) {
-				break
-			}
-		}
+func (b *Bot) routeDriver(ctx context.Context, update telego.Update) error {
+	ctx = withReplyBot(ctx, b.driverBot)
+	if update.CallbackQuery != nil {
+		return b.handleCallback(ctx, *update.CallbackQuery)
 	}
+	return b.HandleDriverMessage(ctx, update)
 }
 
-func (b *Bot) HandleCreateOrder(ctx context.Context, cb telego.CallbackQuery) {
+func (b *Bot) HandleCreateOrder(ctx context.Context, cb telego.CallbackQuery, _ callback.CreateOrder) error {
 	user, err := b.store.UserGet(ctx, cb.From.ID)
 	if err != nil {
-		b.logger.Errorf("store.UserGet: %v", err)
-		return
+		return fmt.Errorf("store.UserGet: %w", err)
 	}
 
-	_, err = b.store.OrderCreate(ctx, user.ID, user.TelegramID)
-	if err != nil {
-		b.logger.Errorf("store.OrderCreate: %s", err)
-		return
+	order, err := b.createDraft(ctx, user, storage.OrderPatch{})
+	if errors.Is(err, errOrderInProgress) {
+		return b.answerOrderInProgress(ctx, cb)
 	}
-
-	_, err = b.customerBot.SendMessage(&telego.SendMessageParams{
-		ChatID: telego.ChatID{ID: user.TelegramID},
-		Text:   "Укажите точку подачи, водитель приедет по указанному адресу.",
-	})
 	if err != nil {
-		b.logger.Errorf("store.SendMessage: %s", err)
+		return err
 	}
+	return b.orderForm.Start(ctx, b.customerConversation(ctx, user.TelegramID), order)
 }
 
-func (b *Bot) HandleArrived(ctx context.Context, cb telego.CallbackQuery) {
-	orderID, err := strconv.Atoi(strings.Split(cb.Data, ":")[1])
+func (b *Bot) HandleArrived(ctx context.Context, cb telego.CallbackQuery, data callback.Arrived) error {
+	p := b.printer(ctx)
+	order, err := b.store.OrderSetStatus(ctx, data.OrderID, models.OrderStatusDriverArrived, cb.From.ID)
 	if err != nil {
-		b.logger.Errorf("strconv.Atoi: %s", err)
-		return
-	}
-	order, err := b.store.OrderGetByID(ctx, orderID)
-	if err != nil {
-		b.logger.Errorf("store.OrderGetByID(%d): %s", orderID, err)
-		return
+		return b.handleTransitionError(ctx, cb, err)
 	}
 
 	if order.TelegramID != 0 {
 		_, err = b.customerBot.SendMessage(&telego.SendMessageParams{
 			ChatID: telego.ChatID{ID: order.TelegramID},
-			Text:   fmt.Sprintf("Водитель Вас ожидает по адресу: %s", *order.Destination),
+			Text:   b.printerFor(ctx, order.TelegramID).T(i18n.DriverWaiting, *order.Destination),
 		})
 		if err != nil {
-			b.logger.Errorf("store.SendMessage: %s", err)
-			return
+			return fmt.Errorf("customerBot.SendMessage: %w", err)
 		}
 	}
 	_, err = b.driverBot.SendMessage(&telego.SendMessageParams{
 		ChatID: telego.ChatID{ID: cb.From.ID},
-		Text:   "Заказ в процессе",
+		Text:   p.T(i18n.AskStartTrip),
 		ReplyMarkup: &telego.InlineKeyboardMarkup{
 			InlineKeyboard: [][]telego.InlineKeyboardButton{
 				{
 					{
-						Text:         "Завершить заказ",
-						CallbackData: fmt.Sprintf("%s:%d", finishedCallback, order.ID),
+						Text:         p.T(i18n.ButtonStartTrip),
+						CallbackData: b.callbacks.Encode(callback.Started{OrderID: order.ID}),
 					},
 				},
 			},
 		},
 	})
 	if err != nil {
-		b.logger.Errorf("store.SendMessage: %s", err)
+		return fmt.Errorf("driverBot.SendMessage: %w", err)
 	}
+	return nil
 }
 
-func (b *Bot) HandleFinished(ctx context.Context, cb telego.CallbackQuery) {
-	orderID, err := strconv.Atoi(strings.Split(cb.Data, ":")[1])
+func (b *Bot) HandleStarted(ctx context.Context, cb telego.CallbackQuery, data callback.Started) error {
+	p := b.printer(ctx)
+	order, err := b.store.OrderSetStatus(ctx, data.OrderID, models.OrderStatusInProgress, cb.From.ID)
 	if err != nil {
-		b.logger.Errorf("strconv.Atoi: %s", err)
-		return
+		return b.handleTransitionError(ctx, cb, err)
 	}
-	order, err := b.store.OrderGetByID(ctx, orderID)
+	_, err = b.driverBot.SendMessage(&telego.SendMessageParams{
+		ChatID: telego.ChatID{ID: cb.From.ID},
+		Text:   p.T(i18n.TripInProgress),
+		ReplyMarkup: &telego.InlineKeyboardMarkup{
+			InlineKeyboard: [][]telego.InlineKeyboardButton{
+				{
+					{
+						Text:         p.T(i18n.ButtonFinishTrip),
+						CallbackData: b.callbacks.Encode(callback.Finished{OrderID: order.ID}),
+					},
+				},
+			},
+		},
+	})
 	if err != nil {
-		b.logger.Errorf("store.OrderGetByID(%d): %s", orderID, err)
-		return
+		return fmt.Errorf("driverBot.SendMessage: %w", err)
+	}
+	return nil
+}
+
+func (b *Bot) HandleFinished(ctx context.Context, cb telego.CallbackQuery, data callback.Finished) error {
+	order, err := b.store.OrderSetStatus(ctx, data.OrderID, models.OrderStatusFinished, cb.From.ID)
+	if err != nil {
+		return b.handleTransitionError(ctx, cb, err)
 	}
 
 	if order.TelegramID != 0 {
+		customer := b.printerFor(ctx, order.TelegramID)
 		_, err = b.customerBot.SendMessage(&telego.SendMessageParams{
-			ChatID: telego.ChatID{ID: order.TelegramID},
-			Text:   "Ваша поездка завершена. Спасибо что воспользовались услугами нашей компании!",
+			ChatID:      telego.ChatID{ID: order.TelegramID},
+			Text:        customer.T(i18n.TripFinished) + "\n\n" + customer.T(i18n.RateTrip),
+			ReplyMarkup: b.ratingKeyboard(order.ID),
 		})
 		if err != nil {
-			b.logger.Errorf("store.SendMessage: %s", err)
-			return
+			return fmt.Errorf("customerBot.SendMessage: %w", err)
 		}
+		b.offerPlaces(ctx, customer, order)
+	}
+	p := b.printer(ctx)
+	text := p.T(i18n.DriverTripFinished)
+	if commission := b.chargeCommission(ctx, p, order.ID); commission != "" {
+		text += "\n\n" + commission
 	}
 	_, err = b.driverBot.SendMessage(&telego.SendMessageParams{
 		ChatID: telego.ChatID{ID: cb.From.ID},
-		Text:   "Заказ завершен. Оплати пожалуйста его - https://yoomoney.ru/bill/pay/2zks2AQkgsA.230415",
+		Text:   text,
 	})
 	if err != nil {
-		b.logger.Errorf("store.SendMessage: %s", err)
+		return fmt.Errorf("driverBot.SendMessage: %w", err)
 	}
+	return nil
 }
 
-func (b *Bot) HandleGetOrder(ctx context.Context, cb telego.CallbackQuery) {
-	orderID, err := strconv.Atoi(strings.Split(cb.Data, ":")[1])
-	if err != nil {
-		b.logger.Errorf("strconv.Atoi: %s", err)
-		return
+func (b *Bot) HandleGetOrder(ctx context.Context, cb telego.CallbackQuery, data callback.TakeOrder) error {
+	p := b.printer(ctx)
+	driver, err := b.store.DriverGetByTelegramID(ctx, cb.From.ID)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return fmt.Errorf("store.DriverGetByTelegramID: %w", err)
+	}
+	if driver == nil || driver.Status != models.DriverStatusApproved {
+		err = b.driverBot.AnswerCallbackQuery(&telego.AnswerCallbackQueryParams{
+			CallbackQueryID: cb.ID,
+			Text:            p.T(i18n.OnlyApprovedDrivers),
+			ShowAlert:       true,
+		})
+		if err != nil {
+			return fmt.Errorf("driverBot.AnswerCallbackQuery: %w", err)
+		}
+		return nil
+	}
+
+	order, err := b.store.OrderClaim(ctx, data.OrderID, cb.From.ID)
+	if errors.Is(err, storage.ErrAlreadyTaken) {
+		err = b.driverBot.AnswerCallbackQuery(&telego.AnswerCallbackQueryParams{
+			CallbackQueryID: cb.ID,
+			Text:            p.T(i18n.OrderAlreadyTaken),
+		})
+		if err != nil {
+			return fmt.Errorf("driverBot.AnswerCallbackQuery: %w", err)
+		}
+		return nil
 	}
-	order, err := b.store.OrderGetByID(ctx, orderID)
 	if err != nil {
-		b.logger.Errorf("store.OrderGetByID(%d): %s", orderID, err)
-		return
+		return b.handleTransitionError(ctx, cb, err)
 	}
 	_, err = b.driverBot.EditMessageText(&telego.EditMessageTextParams{
 		MessageID: cb.Message.MessageID,
 		ChatID:    telego.ChatID{ID: b.driversChatID},
-		Text:      cb.Message.Text + fmt.Sprintf("\nУже взят (%d)", cb.From.ID),
+		Text:      cb.Message.Text + "\n" + b.chatPrinter.T(i18n.OrderTakenBy, cb.From.ID),
 	})
 	if err != nil {
-		b.logger.Errorf("store.EditMessageText: %s", err)
-		return
+		b.log(ctx).Errorf("driverBot.EditMessageText: %v", err)
 	}
 	if order.TelegramID != 0 {
+		customer := b.printerFor(ctx, order.TelegramID)
+		text := customer.T(i18n.DriverFound)
+		if rating := b.driverRatingText(ctx, customer, *order.DriverTelegramID); rating != "" {
+			text += "\n" + rating
+		}
 		_, err = b.customerBot.SendMessage(&telego.SendMessageParams{
-			ChatID: telego.ChatID{ID: order.TelegramID},
-			Text:   "Водитель найден. Скоро он с Вами свяжется.",
+			ChatID:      telego.ChatID{ID: order.TelegramID},
+			Text:        text,
+			ReplyMarkup: b.cancelOrderKeyboard(customer, order.ID),
 		})
 		if err != nil {
-			b.logger.Errorf("store.SendMessage: %s", err)
-			return
+			b.log(ctx).Errorf("customerBot.SendMessage: %v", err)
 		}
 	}
+	text := order.ToPrivate(p, b.location)
+	if order.TelegramID != 0 {
+		// The customer of the bot is reached through the relay, their phone
+		// is not shown to the driver.
+		text = order.ToDriverChat(p, b.location) + "\n\n" + p.T(i18n.DriverRelayHint)
+	}
 	m := &telego.SendMessageParams{
 		ChatID: telego.ChatID{ID: cb.From.ID},
-		Text:   order.ToPrivate(),
+		Text:   text,
 		ReplyMarkup: &telego.InlineKeyboardMarkup{
 			InlineKeyboard: [][]telego.InlineKeyboardButton{
 				{
 					{
-						Text:         "Я на месте",
-						CallbackData: fmt.Sprintf("%s:%d", arrivedCallback, order.ID),
+						Text:         p.T(i18n.ButtonArrived),
+						CallbackData: b.callbacks.Encode(callback.Arrived{OrderID: order.ID}),
 					},
 				},
 			},
@@ -230,167 +352,102 @@ func (b *Bot) HandleGetOrder(ctx context.Context, cb telego.CallbackQuery) {
 	}
 	_, err = b.driverBot.SendMessage(m)
 	if err != nil {
-		b.logger.Errorf("store.SendMessage: %s", err)
+		return fmt.Errorf("driverBot.SendMessage: %w", err)
+	}
+	return b.sendOrderPoints(p, cb.From.ID, order)
+}
+
+func (b *Bot) handleTransitionError(ctx context.Context, cb telego.CallbackQuery, err error) error {
+	var transitionErr *storage.TransitionError
+	if !errors.As(err, &transitionErr) {
+		return fmt.Errorf("store.OrderSetStatus: %w", err)
+	}
+	p := b.printer(ctx)
+	err = b.driverBot.AnswerCallbackQuery(&telego.AnswerCallbackQueryParams{
+		CallbackQueryID: cb.ID,
+		Text:            p.T(i18n.ActionUnavailable, p.T(orderStatusTitles[transitionErr.From])),
+		ShowAlert:       true,
+	})
+	if err != nil {
+		return fmt.Errorf("driverBot.AnswerCallbackQuery: %w", err)
 	}
+	return nil
 }
 
-func (b *Bot) HandleStartCommand(ctx context.Context, update telego.Update) bool {
+func (b *Bot) HandleStartCommand(ctx context.Context, update telego.Update) error {
 	user, err := b.store.UserGet(ctx, update.Message.From.ID)
 	if err != nil {
-		b.logger.Errorf("store.UserGet: %v", err)
-		return true
+		return fmt.Errorf("store.UserGet: %w", err)
 	}
+	p := b.printer(ctx)
 	_, err = b.customerBot.SendMessage(&telego.SendMessageParams{
 		ChatID: telego.ChatID{ID: user.TelegramID},
-		Text:   "Здравствуйте, мы рады принять ваш заказ!",
+		Text:   p.T(i18n.StartGreeting),
 		ReplyMarkup: &telego.InlineKeyboardMarkup{
 			InlineKeyboard: [][]telego.InlineKeyboardButton{
 				{
-					{Text: "Создать заказ", CallbackData: createOrderCallback},
+					{Text: p.T(i18n.ButtonCreateOrder), CallbackData: b.callbacks.Encode(callback.CreateOrder{})},
 				},
 			},
 		},
 	})
 	if err != nil {
-		b.logger.Errorf("store.SendMessage: %s", err)
+		return fmt.Errorf("customerBot.SendMessage: %w", err)
 	}
-	return true
+	return nil
 }
 
-func (b *Bot) HandleMessage(ctx context.Context, update telego.Update) bool {
+func (b *Bot) HandleMessage(ctx context.Context, update telego.Update) error {
 	message := update.Message
 
-	if message != nil {
-		command, _ := telegoutil.ParseCommand(message.Text)
-		handler, ok := b.commandHandlers[command]
-		if ok {
-			return handler(ctx, update)
-		}
-	}
-
-	cb := update.CallbackQuery
-	if cb != nil {
-		b.callbackHandlers[strings.Split(cb.Data, ":")[0]](ctx, *cb)
-		return true
+	command, _ := telegoutil.ParseCommand(message.Text)
+	handler, ok := b.commandHandlers[command]
+	if ok {
+		return handler(ctx, update)
 	}
 
-	user, err := b.store.UserGet(ctx, update.Message.From.ID)
+	user, err := b.store.UserGet(ctx, message.From.ID)
 	if err != nil {
-		b.logger.Errorf("store.UserGet: %v", err)
-		return true
+		return fmt.Errorf("store.UserGet: %w", err)
+	}
+	conversation := b.customerConversation(ctx, user.TelegramID)
+	if user.FormStep != "" {
+		return b.profileForm.Handle(ctx, conversation, user, message)
+	}
+	place, err := b.store.PlaceGetUnnamed(ctx, user.ID)
+	switch {
+	case err == nil:
+		return b.placeForm.Handle(ctx, conversation, place, message)
+	case !errors.Is(err, storage.ErrNotFound):
+		return fmt.Errorf("store.PlaceGetUnnamed: %w", err)
 	}
 
+	p := b.printer(ctx)
 	order, err := b.store.OrderGet(ctx, user.ID)
 	switch {
 	case err == nil:
 	case errors.Is(err, storage.ErrNotFound):
 		_, err = b.customerBot.SendMessage(&telego.SendMessageParams{
 			ChatID: telego.ChatID{ID: user.TelegramID},
-			Text:   "У тебя нет заказа",
+			Text:   p.T(i18n.NoOrder),
 		})
 		if err != nil {
-			b.logger.Errorf("customerBot.SendMessage: %v", err)
-			return true
+			return fmt.Errorf("customerBot.SendMessage: %w", err)
 		}
+		return nil
 	default:
-		b.logger.Errorf("store.OrderGet: %v", err)
-		return true
+		return fmt.Errorf("store.OrderGet: %w", err)
 	}
-	text := update.Message.Text
-
-	if order.Source == nil {
-		_, err = b.store.OrderSetField(ctx, order.ID, "source", text)
-		if err != nil {
-			b.logger.Errorf("store.OrderSetField: %v", err)
-			return true
-		}
-		_, err = b.customerBot.SendMessage(&telego.SendMessageParams{
-			ChatID: telego.ChatID{ID: user.TelegramID},
-			Text:   "Уточните, во сколько к вам приехать?",
-		})
-		if err != nil {
-			b.logger.Errorf("customerBot.SendMessage: %v", err)
-		}
-		return true
+	if relayOpen(order) {
+		return b.relayFromCustomer(ctx, order, message)
 	}
-
-	if order.Time == nil {
-		_, err = b.store.OrderSetField(ctx, order.ID, "time", text)
-		if err != nil {
-			b.logger.Errorf("store.OrderSetField: %v", err)
-			return true
-		}
-		_, err = b.customerBot.SendMessage(&telego.SendMessageParams{
-			ChatID: telego.ChatID{ID: user.TelegramID},
-			Text:   "Укажи конечную точку подачи",
-		})
-		if err != nil {
-			b.logger.Errorf("customerBot.SendMessage: %v", err)
-		}
-		return true
+	if order.Status == models.OrderStatusFinished {
+		return b.handleFeedback(ctx, order, message)
 	}
-
-	if order.Destination == nil {
-		_, err = b.store.OrderSetField(ctx, order.ID, "destination", text)
-		if err != nil {
-			b.logger.Errorf("store.OrderSetField: %v", err)
-			return true
-		}
-		_, err = b.customerBot.SendMessage(&telego.SendMessageParams{
-			ChatID: telego.ChatID{ID: user.TelegramID},
-			Text:   "Укажите Ваш номер телефона для связи?",
-			ReplyMarkup: &telego.ReplyKeyboardMarkup{
-				Keyboard: [][]telego.KeyboardButton{
-					{
-						telego.KeyboardButton{
-							Text:           "",
-							RequestContact: true,
-						},
-					},
-				},
-			},
-		})
-		if err != nil {
-			b.logger.Errorf("customerBot.SendMessage: %v", err)
-		}
-		return true
-	}
-	if order.Phone == nil {
-		order, err := b.store.OrderSetField(ctx, order.ID, "phone", text)
-		if err != nil {
-			b.logger.Errorf("store.OrderSetField: %v", err)
-			return true
-		}
-
-		_, err = b.customerBot.SendMessage(&telego.SendMessageParams{
-			ChatID: telego.ChatID{ID: user.TelegramID},
-			Text:   "Заказ в обработке, мы скоро найдём Вам водителя...",
-		})
-		if err != nil {
-			b.logger.Errorf("customerBot.SendMessage: %v", err)
-			return true
-		}
-		_, err = b.driverBot.SendMessage(&telego.SendMessageParams{
-			ChatID: telego.ChatID{ID: b.driversChatID},
-			Text:   order.ToDriverChat(),
-			ReplyMarkup: &telego.InlineKeyboardMarkup{
-				InlineKeyboard: [][]telego.InlineKeyboardButton{
-					{
-						{
-							Text:         "Возьму заказ",
-							CallbackData: fmt.Sprintf("%s:%d", orderCallback, order.ID),
-						},
-					},
-				},
-			},
-		})
-		if err != nil {
-			b.logger.Errorf("customerBot.SendMessage: %v", err)
-		}
-		return true
+	if order.Status != models.OrderStatusDraft {
+		return nil
 	}
-
-	return true
+	return b.orderForm.Handle(ctx, conversation, order, message)
 }
 
 func (b *Bot) CheckPayments(ctx context.Context, paymentsToCheck <-chan models.Payment) {
@@ -419,9 +476,14 @@ func (b *Bot) CheckPayment(ctx context.Context, payment models.Payment) {
 		logger.Errorf("store.OrderGetByID: %v", err)
 		return
 	}
+	chatID, text := order.TelegramID, b.printerFor(ctx, order.TelegramID).T(i18n.OrderPaid, order.ID)
+	if p.Purpose == models.PaymentPurposeCommission && order.DriverTelegramID != nil {
+		chatID = *order.DriverTelegramID
+		text = b.printerFor(ctx, chatID).T(i18n.CommissionPaid, order.ID)
+	}
 	_, err = b.driverBot.SendMessage(&telego.SendMessageParams{
-		ChatID:    telego.ChatID{ID: order.TelegramID},
-		Text:      fmt.Sprintf(`Заказ MOSCOW-%04d успешно оплачен`, order.ID),
+		ChatID:    telego.ChatID{ID: chatID},
+		Text:      text,
 		ParseMode: "HTML",
 	})
 	if err != nil {
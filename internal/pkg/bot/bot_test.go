@@ -0,0 +1,282 @@
+package bot_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mymmrac/telego"
+	"go.uber.org/zap"
+
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/bot"
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/config"
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/i18n"
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/models"
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/ptr"
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/storage"
+)
+
+const (
+	testToken  = "123456789:AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA"
+	customerID = int64(1001)
+)
+
+// sentMessage is a sendMessage call the bot made to the fake Telegram API.
+type sentMessage struct {
+	ChatID int64  `json:"chat_id"`
+	Text   string `json:"text"`
+}
+
+// telegramServer answers the Bot API calls of both bots and records the
+// sent messages.
+type telegramServer struct {
+	mu       sync.Mutex
+	messages []sentMessage
+}
+
+func (s *telegramServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	result := any(true)
+	if path.Base(r.URL.Path) == "sendMessage" {
+		var m sentMessage
+		if err := json.NewDecoder(r.Body).Decode(&m); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.mu.Lock()
+		s.messages = append(s.messages, m)
+		s.mu.Unlock()
+		result = telego.Message{MessageID: len(s.messages), Chat: telego.Chat{ID: m.ChatID}}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "result": result})
+}
+
+func (s *telegramServer) sent() []sentMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]sentMessage(nil), s.messages...)
+}
+
+// paymentProcessor reports the payments with a fixed status.
+type paymentProcessor struct {
+	status models.PaymentStatus
+}
+
+func (p paymentProcessor) CreatePayment(context.Context, i18n.Printer, models.Order) (string, error) {
+	return "", nil
+}
+
+func (p paymentProcessor) CheckOrder(_ context.Context, payment models.Payment) (*models.Payment, error) {
+	payment.Status = p.status
+	return &payment, nil
+}
+
+func (p paymentProcessor) ChargeCancellation(context.Context, i18n.Printer, models.Order) (string, error) {
+	return "", nil
+}
+
+func (p paymentProcessor) ChargeCommission(context.Context, i18n.Printer, int, models.Money) (string, error) {
+	return "", nil
+}
+
+func newTestBot(t *testing.T, store storage.Repository, processor paymentProcessor) (*bot.Bot, *telegramServer) {
+	t.Helper()
+	api := &telegramServer{}
+	srv := httptest.NewServer(api)
+	t.Cleanup(srv.Close)
+
+	tb, err := telego.NewBot(testToken, telego.WithAPIServer(srv.URL), telego.WithDiscardLogger())
+	if err != nil {
+		t.Fatalf("telego.NewBot: %v", err)
+	}
+	loc, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		t.Fatalf("time.LoadLocation: %v", err)
+	}
+	b := bot.New(
+		tb,
+		tb,
+		processor,
+		nil,
+		store,
+		zap.NewNop().Sugar(),
+		config.Telegram{CallbackSecret: "secret"},
+		config.Commission{},
+		loc,
+	)
+	return b, api
+}
+
+func textMessage(text string) telego.Update {
+	return telego.Update{Message: &telego.Message{
+		Text: text,
+		From: &telego.User{ID: customerID},
+		Chat: telego.Chat{ID: customerID, Type: telego.ChatTypePrivate},
+	}}
+}
+
+// draftOrder creates a draft order of the customer waiting at the given
+// step of the order form.
+func draftOrder(t *testing.T, store *storage.MemoryStore, step string, patch storage.OrderPatch) {
+	t.Helper()
+	ctx := context.Background()
+	user, err := store.UserGet(ctx, customerID)
+	if err != nil {
+		t.Fatalf("store.UserGet: %v", err)
+	}
+	order, err := store.OrderCreate(ctx, user.ID, customerID)
+	if err != nil {
+		t.Fatalf("store.OrderCreate: %v", err)
+	}
+	patch.FormStep = &step
+	if _, err = store.OrderUpdate(ctx, order.ID, patch); err != nil {
+		t.Fatalf("store.OrderUpdate: %v", err)
+	}
+}
+
+func TestHandleMessage(t *testing.T) {
+	p := i18n.New(i18n.Default)
+	tests := []struct {
+		name  string
+		setup func(t *testing.T, store *storage.MemoryStore)
+		text  string
+		// reply is the last text the customer gets.
+		reply string
+		check func(t *testing.T, order *models.Order)
+	}{
+		{
+			name:  "start command",
+			text:  "/start",
+			reply: p.T(i18n.StartGreeting),
+		},
+		{
+			name:  "no order",
+			text:  "hello",
+			reply: p.T(i18n.NoOrder),
+		},
+		{
+			name: "source is saved and the time is asked",
+			setup: func(t *testing.T, store *storage.MemoryStore) {
+				draftOrder(t, store, "source", storage.OrderPatch{})
+			},
+			text:  "Тверская, 1",
+			reply: p.T(i18n.AskTime),
+			check: func(t *testing.T, order *models.Order) {
+				if order.Source == nil || *order.Source != "Тверская, 1" {
+					t.Errorf("order.Source = %v, want %q", order.Source, "Тверская, 1")
+				}
+				if order.SourcePoint != nil {
+					t.Errorf("order.SourcePoint = %v, want nil", order.SourcePoint)
+				}
+			},
+		},
+		{
+			name: "typed source drops the shared point",
+			setup: func(t *testing.T, store *storage.MemoryStore) {
+				draftOrder(t, store, "source", storage.OrderPatch{
+					Source:      ptr.Of("Point on the map"),
+					SourcePoint: &models.Point{Latitude: 55.75, Longitude: 37.61},
+				})
+			},
+			text:  "Тверская, 1",
+			reply: p.T(i18n.AskTime),
+			check: func(t *testing.T, order *models.Order) {
+				if order.SourcePoint != nil {
+					t.Errorf("order.SourcePoint = %v, want nil", order.SourcePoint)
+				}
+			},
+		},
+		{
+			name: "unrecognized time is asked again",
+			setup: func(t *testing.T, store *storage.MemoryStore) {
+				draftOrder(t, store, "time", storage.OrderPatch{Source: ptr.Of("Тверская, 1")})
+			},
+			text:  "whenever",
+			reply: p.T(i18n.TimeUnrecognized),
+			check: func(t *testing.T, order *models.Order) {
+				if order.Time != nil {
+					t.Errorf("order.Time = %q, want nil", *order.Time)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := storage.NewMemoryStore()
+			if tt.setup != nil {
+				tt.setup(t, store)
+			}
+			b, api := newTestBot(t, store, paymentProcessor{})
+
+			if err := b.HandleMessage(context.Background(), textMessage(tt.text)); err != nil {
+				t.Fatalf("HandleMessage: %v", err)
+			}
+
+			sent := api.sent()
+			if len(sent) == 0 {
+				t.Fatalf("no message sent, want %q", tt.reply)
+			}
+			last := sent[len(sent)-1]
+			if last.ChatID != customerID || last.Text != tt.reply {
+				t.Errorf("sent %q to %d, want %q to %d", last.Text, last.ChatID, tt.reply, customerID)
+			}
+			if tt.check == nil {
+				return
+			}
+			user, err := store.UserGet(context.Background(), customerID)
+			if err != nil {
+				t.Fatalf("store.UserGet: %v", err)
+			}
+			order, err := store.OrderGet(context.Background(), user.ID)
+			if err != nil {
+				t.Fatalf("store.OrderGet: %v", err)
+			}
+			tt.check(t, order)
+		})
+	}
+}
+
+func TestCheckPayment(t *testing.T) {
+	tests := []struct {
+		status   models.PaymentStatus
+		notified bool
+	}{
+		{models.PaymentStatusPending, false},
+		{models.PaymentStatusCanceled, false},
+		{models.PaymentStatusSucceeded, true},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			ctx := context.Background()
+			store := storage.NewMemoryStore()
+			user, err := store.UserGet(ctx, customerID)
+			if err != nil {
+				t.Fatalf("store.UserGet: %v", err)
+			}
+			order, err := store.OrderCreate(ctx, user.ID, customerID)
+			if err != nil {
+				t.Fatalf("store.OrderCreate: %v", err)
+			}
+			b, api := newTestBot(t, store, paymentProcessor{status: tt.status})
+
+			b.CheckPayment(ctx, models.Payment{OrderID: order.ID, Status: models.PaymentStatusPending})
+
+			sent := api.sent()
+			if !tt.notified {
+				if len(sent) != 0 {
+					t.Errorf("sent %v, want nothing", sent)
+				}
+				return
+			}
+			want := sentMessage{ChatID: customerID, Text: i18n.New(i18n.Default).T(i18n.OrderPaid, order.ID)}
+			if len(sent) != 1 || sent[0] != want {
+				t.Errorf("sent %v, want %v", sent, want)
+			}
+		})
+	}
+}
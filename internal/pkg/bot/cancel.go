@@ -0,0 +1,115 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/mymmrac/telego"
+
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/callback"
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/i18n"
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/models"
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/storage"
+)
+
+func (b *Bot) cancelOrderKeyboard(p i18n.Printer, orderID int) *telego.InlineKeyboardMarkup {
+	return &telego.InlineKeyboardMarkup{
+		InlineKeyboard: [][]telego.InlineKeyboardButton{
+			{
+				{
+					Text:         p.T(i18n.ButtonCancelOrder),
+					CallbackData: b.callbacks.Encode(callback.CancelOrder{OrderID: orderID}),
+				},
+			},
+		},
+	}
+}
+
+func (b *Bot) HandleCancelCommand(ctx context.Context, update telego.Update) error {
+	user, err := b.store.UserGet(ctx, update.Message.From.ID)
+	if err != nil {
+		return fmt.Errorf("store.UserGet: %w", err)
+	}
+	order, err := b.store.OrderGetActive(ctx, user.ID)
+	if errors.Is(err, storage.ErrNotFound) {
+		return b.sendCustomer(user.TelegramID, b.printer(ctx).T(i18n.NoActiveOrder))
+	}
+	if err != nil {
+		return fmt.Errorf("store.OrderGetActive: %w", err)
+	}
+	return b.cancelOrder(ctx, user, order)
+}
+
+func (b *Bot) HandleCancelOrder(ctx context.Context, cb telego.CallbackQuery, data callback.CancelOrder) error {
+	orderID := data.OrderID
+	user, err := b.store.UserGet(ctx, cb.From.ID)
+	if err != nil {
+		return fmt.Errorf("store.UserGet: %w", err)
+	}
+	order, err := b.store.OrderGetByID(ctx, orderID)
+	if err != nil {
+		return fmt.Errorf("store.OrderGetByID(%d): %w", orderID, err)
+	}
+	if order.UserID == nil || *order.UserID != user.ID {
+		return fmt.Errorf("user %s tried to cancel order %d of another user", user.ID, orderID)
+	}
+	err = b.customerBot.AnswerCallbackQuery(&telego.AnswerCallbackQueryParams{CallbackQueryID: cb.ID})
+	if err != nil {
+		b.log(ctx).Errorf("customerBot.AnswerCallbackQuery: %v", err)
+	}
+	return b.cancelOrder(ctx, user, order)
+}
+
+func (b *Bot) cancelOrder(ctx context.Context, user *models.User, order *models.Order) error {
+	p := b.printer(ctx)
+	cancelled, err := b.store.OrderSetStatus(ctx, order.ID, models.OrderStatusCancelled, user.TelegramID)
+	var transitionErr *storage.TransitionError
+	if errors.As(err, &transitionErr) {
+		return b.sendCustomer(user.TelegramID, p.T(i18n.CannotCancel, p.T(orderStatusTitles[transitionErr.From])))
+	}
+	if err != nil {
+		return fmt.Errorf("store.OrderSetStatus: %w", err)
+	}
+
+	if cancelled.DriversChatMessageID != nil {
+		_, err = b.driverBot.EditMessageText(&telego.EditMessageTextParams{
+			ChatID:    telego.ChatID{ID: b.driversChatID},
+			MessageID: *cancelled.DriversChatMessageID,
+			Text:      cancelled.ToDriverChat(b.chatPrinter, b.location) + "\n" + b.chatPrinter.T(i18n.OrderCancelledMark),
+		})
+		if err != nil {
+			b.log(ctx).Errorf("driverBot.EditMessageText: %v", err)
+		}
+	}
+	if cancelled.DriverTelegramID != nil {
+		_, err = b.driverBot.SendMessage(&telego.SendMessageParams{
+			ChatID: telego.ChatID{ID: *cancelled.DriverTelegramID},
+			Text:   b.printerFor(ctx, *cancelled.DriverTelegramID).T(i18n.CustomerCancelled, cancelled.ID),
+		})
+		if err != nil {
+			b.log(ctx).Errorf("driverBot.SendMessage: %v", err)
+		}
+	}
+
+	text := p.T(i18n.OrderCancelled)
+	paymentURL, err := b.processor.ChargeCancellation(ctx, p, *cancelled)
+	if err != nil {
+		b.log(ctx).Errorf("processor.ChargeCancellation: %v", err)
+	}
+	if paymentURL != "" {
+		text = p.T(i18n.OrderCancelledFee, paymentURL)
+	}
+	return b.sendCustomer(user.TelegramID, text)
+}
+
+func (b *Bot) sendCustomer(chatID int64, text string) error {
+	_, err := b.customerBot.SendMessage(&telego.SendMessageParams{
+		ChatID: telego.ChatID{ID: chatID},
+		Text:   text,
+	})
+	if err != nil {
+		return fmt.Errorf("customerBot.SendMessage: %w", err)
+	}
+	return nil
+}
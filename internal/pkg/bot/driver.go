@@ -0,0 +1,137 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/mymmrac/telego"
+	"github.com/mymmrac/telego/telegoutil"
+
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/callback"
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/i18n"
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/models"
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/storage"
+)
+
+func (b *Bot) HandleDriverMessage(ctx context.Context, update telego.Update) error {
+	message := update.Message
+
+	if message != nil {
+		command, _ := telegoutil.ParseCommand(message.Text)
+		handler, ok := b.driverCommandHandlers[command]
+		if ok {
+			return handler(ctx, update)
+		}
+	}
+
+	if message == nil || message.Chat.Type != telego.ChatTypePrivate {
+		return nil
+	}
+	if message.Contact != nil {
+		return b.HandleDriverContact(ctx, update)
+	}
+	return b.HandleDriverRelay(ctx, update)
+}
+
+func (b *Bot) HandleDriverStartCommand(ctx context.Context, update telego.Update) error {
+	p := b.printer(ctx)
+	_, err := b.driverBot.SendMessage(&telego.SendMessageParams{
+		ChatID: telego.ChatID{ID: update.Message.From.ID},
+		Text:   p.T(i18n.DriverStart),
+		ReplyMarkup: &telego.ReplyKeyboardMarkup{
+			Keyboard: [][]telego.KeyboardButton{
+				{
+					{Text: p.T(i18n.ButtonShareContact), RequestContact: true},
+				},
+			},
+			ResizeKeyboard:  true,
+			OneTimeKeyboard: true,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("driverBot.SendMessage: %w", err)
+	}
+	return nil
+}
+
+func (b *Bot) HandleDriverContact(ctx context.Context, update telego.Update) error {
+	message := update.Message
+	p := b.printer(ctx)
+	text := ""
+	switch {
+	case message.Contact.UserID != message.From.ID:
+		text = p.T(i18n.ShareOwnContact)
+	default:
+		driver, err := b.store.DriverLinkTelegram(ctx, message.Contact.PhoneNumber, message.From.ID)
+		switch {
+		case errors.Is(err, storage.ErrNotFound):
+			text = p.T(i18n.ApplicationNotFound)
+		case errors.Is(err, storage.ErrDriverLinked):
+			text = p.T(i18n.ApplicationLinked)
+		case err != nil:
+			return fmt.Errorf("store.DriverLinkTelegram: %w", err)
+		case driver.Status == models.DriverStatusApproved:
+			text = p.T(i18n.ApplicationApproved)
+		case driver.Status == models.DriverStatusBlocked:
+			text = p.T(i18n.ApplicationRejected)
+		default:
+			text = p.T(i18n.ApplicationPending)
+		}
+	}
+
+	_, err := b.driverBot.SendMessage(&telego.SendMessageParams{
+		ChatID:      telego.ChatID{ID: message.From.ID},
+		Text:        text,
+		ReplyMarkup: &telego.ReplyKeyboardRemove{RemoveKeyboard: true},
+	})
+	if err != nil {
+		return fmt.Errorf("driverBot.SendMessage: %w", err)
+	}
+	return nil
+}
+
+func (b *Bot) HandleApproveDriver(ctx context.Context, cb telego.CallbackQuery, data callback.ApproveDriver) error {
+	return b.setDriverStatus(ctx, cb, data.DriverID, models.DriverStatusApproved, i18n.ResolutionApproved, i18n.ApplicationApproved)
+}
+
+func (b *Bot) HandleRejectDriver(ctx context.Context, cb telego.CallbackQuery, data callback.RejectDriver) error {
+	return b.setDriverStatus(ctx, cb, data.DriverID, models.DriverStatusBlocked, i18n.ResolutionRejected, i18n.ApplicationRejected)
+}
+
+func (b *Bot) setDriverStatus(
+	ctx context.Context,
+	cb telego.CallbackQuery,
+	driverID int,
+	status models.DriverStatus,
+	resolution i18n.Key,
+	notification i18n.Key,
+) error {
+	if cb.Message == nil || cb.Message.Chat.ID != b.adminChatID {
+		return fmt.Errorf("driver status change from chat outside admin chat: %s", cb.Data)
+	}
+	driver, err := b.store.DriverSetStatus(ctx, driverID, status)
+	if err != nil {
+		return fmt.Errorf("store.DriverSetStatus(%d): %w", driverID, err)
+	}
+
+	_, err = b.driverBot.EditMessageText(&telego.EditMessageTextParams{
+		MessageID: cb.Message.MessageID,
+		ChatID:    telego.ChatID{ID: b.adminChatID},
+		Text:      cb.Message.Text + fmt.Sprintf("\n%s (%d)", b.chatPrinter.T(resolution), cb.From.ID),
+	})
+	if err != nil {
+		b.log(ctx).Errorf("driverBot.EditMessageText: %v", err)
+	}
+
+	if driver.TelegramID != nil {
+		_, err = b.driverBot.SendMessage(&telego.SendMessageParams{
+			ChatID: telego.ChatID{ID: *driver.TelegramID},
+			Text:   b.printerFor(ctx, *driver.TelegramID).T(notification),
+		})
+		if err != nil {
+			return fmt.Errorf("driverBot.SendMessage: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mymmrac/telego"
+
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/callback"
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/i18n"
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/models"
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/storage"
+)
+
+const ordersPageSize = 5
+
+func (b *Bot) HandleOrdersCommand(ctx context.Context, update telego.Update) error {
+	user, err := b.store.UserGet(ctx, update.Message.From.ID)
+	if err != nil {
+		return fmt.Errorf("store.UserGet: %w", err)
+	}
+	text, markup, err := b.orderHistory(ctx, user, 0)
+	if err != nil {
+		return err
+	}
+	_, err = b.customerBot.SendMessage(&telego.SendMessageParams{
+		ChatID:      telego.ChatID{ID: user.TelegramID},
+		Text:        text,
+		ReplyMarkup: markup,
+	})
+	if err != nil {
+		return fmt.Errorf("customerBot.SendMessage: %w", err)
+	}
+	return nil
+}
+
+func (b *Bot) HandleOrderHistory(ctx context.Context, cb telego.CallbackQuery, data callback.OrderHistory) error {
+	user, err := b.store.UserGet(ctx, cb.From.ID)
+	if err != nil {
+		return fmt.Errorf("store.UserGet: %w", err)
+	}
+	text, markup, err := b.orderHistory(ctx, user, data.Page)
+	if err != nil {
+		return err
+	}
+	err = b.customerBot.AnswerCallbackQuery(&telego.AnswerCallbackQueryParams{CallbackQueryID: cb.ID})
+	if err != nil {
+		b.log(ctx).Errorf("customerBot.AnswerCallbackQuery: %v", err)
+	}
+	if cb.Message == nil {
+		return nil
+	}
+	_, err = b.customerBot.EditMessageText(&telego.EditMessageTextParams{
+		ChatID:      telego.ChatID{ID: cb.Message.Chat.ID},
+		MessageID:   cb.Message.MessageID,
+		Text:        text,
+		ReplyMarkup: markup,
+	})
+	if err != nil {
+		return fmt.Errorf("customerBot.EditMessageText: %w", err)
+	}
+	return nil
+}
+
+// orderHistory renders a page of the user's placed orders with buttons to
+// repeat them and to turn the pages.
+func (b *Bot) orderHistory(
+	ctx context.Context,
+	user *models.User,
+	page int,
+) (string, *telego.InlineKeyboardMarkup, error) {
+	if page < 0 {
+		page = 0
+	}
+	// One more order tells whether there is an older page.
+	orders, err := b.store.OrderList(ctx, user.ID, ordersPageSize+1, page*ordersPageSize)
+	if err != nil {
+		return "", nil, fmt.Errorf("store.OrderList: %w", err)
+	}
+	p := b.printer(ctx)
+	if len(orders) == 0 && page == 0 {
+		return p.T(i18n.NoOrders), &telego.InlineKeyboardMarkup{
+			InlineKeyboard: [][]telego.InlineKeyboardButton{
+				{
+					{Text: p.T(i18n.ButtonCreateOrder), CallbackData: b.callbacks.Encode(callback.CreateOrder{})},
+				},
+			},
+		}, nil
+	}
+	older := len(orders) > ordersPageSize
+	if older {
+		orders = orders[:ordersPageSize]
+	}
+
+	texts := []string{p.T(i18n.OrderHistory, page+1)}
+	rows := [][]telego.InlineKeyboardButton{}
+	for i := range orders {
+		o := &orders[i]
+		texts = append(texts, b.orderHistoryItem(p, o))
+		if o.Source != nil && o.Destination != nil {
+			rows = append(rows, []telego.InlineKeyboardButton{{
+				Text:         p.T(i18n.ButtonRepeatOrder, o.ID),
+				CallbackData: b.callbacks.Encode(callback.RepeatOrder{OrderID: o.ID}),
+			}})
+		}
+	}
+	var navigation []telego.InlineKeyboardButton
+	if page > 0 {
+		navigation = append(navigation, telego.InlineKeyboardButton{
+			Text:         p.T(i18n.ButtonNewerOrders),
+			CallbackData: b.callbacks.Encode(callback.OrderHistory{Page: page - 1}),
+		})
+	}
+	if older {
+		navigation = append(navigation, telego.InlineKeyboardButton{
+			Text:         p.T(i18n.ButtonOlderOrders),
+			CallbackData: b.callbacks.Encode(callback.OrderHistory{Page: page + 1}),
+		})
+	}
+	if len(navigation) != 0 {
+		rows = append(rows, navigation)
+	}
+	return strings.Join(texts, "\n\n"), &telego.InlineKeyboardMarkup{InlineKeyboard: rows}, nil
+}
+
+// orderHistoryItem shows the pickup time of the order, or when it was made
+// if it has none.
+func (b *Bot) orderHistoryItem(p i18n.Printer, o *models.Order) string {
+	date := o.CreatedAt
+	if o.ScheduledAt != nil {
+		date = *o.ScheduledAt
+	}
+	address := func(s *string) string {
+		if s == nil {
+			return "—"
+		}
+		return *s
+	}
+	return p.T(
+		i18n.OrderHistoryItem,
+		o.ID,
+		date.In(b.location).Format("02.01.2006 15:04"),
+		p.T(orderStatusTitles[o.Status]),
+		address(o.Source),
+		address(o.Destination),
+	)
+}
+
+// HandleRepeatOrder starts a new order with the addresses of a past one and
+// asks only for the time, as the phone is usually remembered too.
+func (b *Bot) HandleRepeatOrder(ctx context.Context, cb telego.CallbackQuery, data callback.RepeatOrder) error {
+	user, err := b.store.UserGet(ctx, cb.From.ID)
+	if err != nil {
+		return fmt.Errorf("store.UserGet: %w", err)
+	}
+	past, err := b.store.OrderGetByID(ctx, data.OrderID)
+	if err != nil {
+		return fmt.Errorf("store.OrderGetByID(%d): %w", data.OrderID, err)
+	}
+	if past.UserID == nil || *past.UserID != user.ID {
+		return fmt.Errorf("user %s tried to repeat order %d of another user", user.ID, past.ID)
+	}
+	if past.Source == nil || past.Destination == nil {
+		return fmt.Errorf("order %d has no addresses to repeat", past.ID)
+	}
+
+	order, err := b.createDraft(ctx, user, storage.OrderPatch{
+		Source:           past.Source,
+		SourcePoint:      past.SourcePoint,
+		Destination:      past.Destination,
+		DestinationPoint: past.DestinationPoint,
+	})
+	if errors.Is(err, errOrderInProgress) {
+		return b.answerOrderInProgress(ctx, cb)
+	}
+	if err != nil {
+		return err
+	}
+	err = b.customerBot.AnswerCallbackQuery(&telego.AnswerCallbackQueryParams{CallbackQueryID: cb.ID})
+	if err != nil {
+		b.log(ctx).Errorf("customerBot.AnswerCallbackQuery: %v", err)
+	}
+	return b.orderForm.Goto(ctx, b.customerConversation(ctx, user.TelegramID), order, orderStepTime)
+}
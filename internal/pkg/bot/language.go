@@ -0,0 +1,115 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/mymmrac/telego"
+
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/callback"
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/i18n"
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/storage"
+)
+
+type printerKey struct{}
+
+type replyBotKey struct{}
+
+// withLanguage remembers the language Telegram reports for a new user and
+// puts the printer of the user's language into the context.
+func (b *Bot) withLanguage(next MessageHandler) MessageHandler {
+	return func(ctx context.Context, update telego.Update) error {
+		from := updateFrom(update)
+		if from == nil || from.IsBot {
+			return next(ctx, update)
+		}
+		user, err := b.store.UserEnsureLanguage(ctx, from.ID, string(i18n.FromTelegram(from.LanguageCode)))
+		if err != nil {
+			return fmt.Errorf("store.UserEnsureLanguage: %w", err)
+		}
+		ctx = context.WithValue(ctx, printerKey{}, i18n.New(i18n.Lookup(user.Language)))
+		return next(ctx, update)
+	}
+}
+
+// printer returns the printer of the user who sent the update.
+func (b *Bot) printer(ctx context.Context) i18n.Printer {
+	if p, ok := ctx.Value(printerKey{}).(i18n.Printer); ok {
+		return p
+	}
+	return i18n.New(i18n.Default)
+}
+
+// printerFor returns the printer of another user, e.g. the customer who is
+// notified about an action of the driver.
+func (b *Bot) printerFor(ctx context.Context, telegramID int64) i18n.Printer {
+	user, err := b.store.UserGetByTelegramID(ctx, telegramID)
+	if err != nil {
+		// A driver who never wrote to the customer bot has no user yet.
+		if !errors.Is(err, storage.ErrNotFound) {
+			b.log(ctx).Errorf("store.UserGetByTelegramID(%d): %v", telegramID, err)
+		}
+		return i18n.New(i18n.Default)
+	}
+	return i18n.New(i18n.Lookup(user.Language))
+}
+
+func withReplyBot(ctx context.Context, bot *telego.Bot) context.Context {
+	return context.WithValue(ctx, replyBotKey{}, bot)
+}
+
+// replyBot returns the bot that received the update, for handlers shared by
+// both bots.
+func (b *Bot) replyBot(ctx context.Context) *telego.Bot {
+	if bot, ok := ctx.Value(replyBotKey{}).(*telego.Bot); ok {
+		return bot
+	}
+	return b.customerBot
+}
+
+func (b *Bot) HandleLanguageCommand(ctx context.Context, update telego.Update) error {
+	buttons := make([]telego.InlineKeyboardButton, 0, len(i18n.Langs))
+	for _, lang := range i18n.Langs {
+		buttons = append(buttons, telego.InlineKeyboardButton{
+			Text:         i18n.New(lang).T(i18n.LanguageName),
+			CallbackData: b.callbacks.Encode(callback.SetLanguage{Lang: string(lang)}),
+		})
+	}
+	_, err := b.replyBot(ctx).SendMessage(&telego.SendMessageParams{
+		ChatID: telego.ChatID{ID: update.Message.Chat.ID},
+		Text:   b.printer(ctx).T(i18n.ChooseLanguage),
+		ReplyMarkup: &telego.InlineKeyboardMarkup{
+			InlineKeyboard: [][]telego.InlineKeyboardButton{buttons},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("SendMessage: %w", err)
+	}
+	return nil
+}
+
+func (b *Bot) HandleSetLanguage(ctx context.Context, cb telego.CallbackQuery, data callback.SetLanguage) error {
+	user, err := b.store.UserSetLanguage(ctx, cb.From.ID, string(i18n.Lookup(data.Lang)))
+	if err != nil {
+		return fmt.Errorf("store.UserSetLanguage: %w", err)
+	}
+	p := i18n.New(i18n.Lookup(user.Language))
+	bot := b.replyBot(ctx)
+	err = bot.AnswerCallbackQuery(&telego.AnswerCallbackQueryParams{CallbackQueryID: cb.ID})
+	if err != nil {
+		b.log(ctx).Errorf("AnswerCallbackQuery: %v", err)
+	}
+	if cb.Message == nil {
+		return nil
+	}
+	_, err = bot.EditMessageText(&telego.EditMessageTextParams{
+		ChatID:    telego.ChatID{ID: cb.Message.Chat.ID},
+		MessageID: cb.Message.MessageID,
+		Text:      p.T(i18n.LanguageChosen),
+	})
+	if err != nil {
+		return fmt.Errorf("EditMessageText: %w", err)
+	}
+	return nil
+}
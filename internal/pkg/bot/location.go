@@ -0,0 +1,43 @@
+package bot
+
+import (
+	"fmt"
+
+	"github.com/mymmrac/telego"
+
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/i18n"
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/models"
+)
+
+// sendOrderPoints sends the driver the pickup and destination as venues so
+// they can be opened in a navigation app.
+func (b *Bot) sendOrderPoints(p i18n.Printer, chatID int64, order *models.Order) error {
+	points := []struct {
+		title   string
+		address *string
+		point   *models.Point
+	}{
+		{p.T(i18n.VenueSource), order.Source, order.SourcePoint},
+		{p.T(i18n.VenueDestination), order.Destination, order.DestinationPoint},
+	}
+	for _, point := range points {
+		if point.point == nil {
+			continue
+		}
+		address := ""
+		if point.address != nil {
+			address = *point.address
+		}
+		_, err := b.driverBot.SendVenue(&telego.SendVenueParams{
+			ChatID:    telego.ChatID{ID: chatID},
+			Latitude:  point.point.Latitude,
+			Longitude: point.point.Longitude,
+			Title:     point.title,
+			Address:   address,
+		})
+		if err != nil {
+			return fmt.Errorf("driverBot.SendVenue: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package bot
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"github.com/mymmrac/telego"
+	"github.com/mymmrac/telego/telegoutil"
+	"go.uber.org/zap"
+
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/callback"
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/i18n"
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/log"
+)
+
+// updateTimeout bounds the handling of a single update, including the
+// database and Telegram calls it makes.
+const updateTimeout = 30 * time.Second
+
+var updateMetrics = expvar.NewMap("bot_updates")
+
+// Middleware wraps a handler of a whole update.
+type Middleware func(next MessageHandler) MessageHandler
+
+// chain applies the middlewares so that the first one is the outermost.
+func chain(handler MessageHandler, middlewares ...Middleware) MessageHandler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+type loggerKey struct{}
+
+// log returns the logger of the update being handled.
+func (b *Bot) log(ctx context.Context) *zap.SugaredLogger {
+	if logger, ok := ctx.Value(loggerKey{}).(*zap.SugaredLogger); ok {
+		return logger
+	}
+	return b.logger
+}
+
+// withLogger puts a logger carrying the update and user IDs into the
+// context and records how long the update took and how it ended.
+func (b *Bot) withLogger(next MessageHandler) MessageHandler {
+	return func(ctx context.Context, update telego.Update) error {
+		logger := b.logger.With(log.UpdateID(update.UpdateID))
+		if from := updateFrom(update); from != nil {
+			logger = logger.With(log.TelegramID(from.ID))
+		}
+		route := updateRoute(update)
+		logger = logger.With(zap.String("route", route))
+		ctx = context.WithValue(ctx, loggerKey{}, logger)
+
+		start := time.Now()
+		err := next(ctx, update)
+		duration := time.Since(start)
+
+		updateMetrics.Add("handled", 1)
+		updateMetrics.Add("duration_ms", duration.Milliseconds())
+		if err != nil {
+			updateMetrics.Add("failed", 1)
+			logger.Errorw("update failed", "duration", duration, "error", err)
+			return err
+		}
+		logger.Infow("update handled", "duration", duration)
+		return nil
+	}
+}
+
+// recoverPanic turns a panic in a handler into an error so that one bad
+// update does not take the process down.
+func (b *Bot) recoverPanic(next MessageHandler) MessageHandler {
+	return func(ctx context.Context, update telego.Update) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				updateMetrics.Add("panics", 1)
+				err = fmt.Errorf("panic: %v\n%s", r, debug.Stack())
+			}
+		}()
+		return next(ctx, update)
+	}
+}
+
+func (b *Bot) withTimeout(next MessageHandler) MessageHandler {
+	return func(ctx context.Context, update telego.Update) error {
+		ctx, cancel := context.WithTimeout(ctx, updateTimeout)
+		defer cancel()
+		return next(ctx, update)
+	}
+}
+
+// handleCallback decodes the callback data and routes it by its action.
+func (b *Bot) handleCallback(ctx context.Context, cb telego.CallbackQuery) error {
+	data, err := b.callbacks.Decode(cb.Data)
+	if err != nil {
+		// Buttons of old messages carry data of a previous version or
+		// secret, the user is asked to use fresh ones instead.
+		b.log(ctx).Warnf("callbacks.Decode(%q): %v", cb.Data, err)
+		err = b.replyBot(ctx).AnswerCallbackQuery(&telego.AnswerCallbackQueryParams{
+			CallbackQueryID: cb.ID,
+			Text:            b.printer(ctx).T(i18n.ButtonOutdated),
+			ShowAlert:       true,
+		})
+		if err != nil {
+			return fmt.Errorf("AnswerCallbackQuery: %w", err)
+		}
+		return nil
+	}
+	handler, ok := b.callbackHandlers[data.Action()]
+	if !ok {
+		return fmt.Errorf("no handler for callback %s", data.Action())
+	}
+	return handler(ctx, cb, data)
+}
+
+// onCallback adapts a handler of one callback type to CallbackHandler.
+func onCallback[T callback.Data](handler func(ctx context.Context, cb telego.CallbackQuery, data T) error) CallbackHandler {
+	return func(ctx context.Context, cb telego.CallbackQuery, data callback.Data) error {
+		typed, ok := data.(T)
+		if !ok {
+			return fmt.Errorf("callback %s: unexpected data %T", data.Action(), data)
+		}
+		return handler(ctx, cb, typed)
+	}
+}
+
+func updateFrom(update telego.Update) *telego.User {
+	switch {
+	case update.Message != nil:
+		return update.Message.From
+	case update.CallbackQuery != nil:
+		return &update.CallbackQuery.From
+	case update.ChannelPost != nil:
+		return update.ChannelPost.From
+	}
+	return nil
+}
+
+func updateRoute(update telego.Update) string {
+	switch {
+	case update.CallbackQuery != nil:
+		return "callback:" + string(callback.ActionOf(update.CallbackQuery.Data))
+	case update.Message != nil:
+		if command, _ := telegoutil.ParseCommand(update.Message.Text); command != "" {
+			return "command:" + command
+		}
+		return "message"
+	case update.ChannelPost != nil:
+		return "channel_post"
+	}
+	return "unknown"
+}
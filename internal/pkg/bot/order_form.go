@@ -0,0 +1,379 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mymmrac/telego"
+
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/callback"
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/dialog"
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/i18n"
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/models"
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/phone"
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/pickuptime"
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/storage"
+)
+
+const (
+	orderStepSource      = "source"
+	orderStepTime        = "time"
+	orderStepDestination = "destination"
+	orderStepPhone       = "phone"
+	// orderStepReview is the position of a filled order waiting for the
+	// customer to confirm it. It is not a step of the form, so a message at
+	// this position shows the review card again.
+	orderStepReview = "review"
+)
+
+// newOrderForm builds the form a customer fills in to place an order.
+func (b *Bot) newOrderForm() *dialog.Flow[*models.Order] {
+	return &dialog.Flow[*models.Order]{
+		Steps: []dialog.Step[*models.Order]{
+			{
+				Name:      orderStepSource,
+				Prompt:    i18n.AskSource,
+				Input:     dialog.InputLocation,
+				Shortcuts: b.placeShortcuts,
+				Filled:    func(o *models.Order) bool { return o.Source != nil },
+				Save: func(ctx context.Context, o *models.Order, in dialog.Input) error {
+					address := b.addressText(in)
+					return b.updateOrder(ctx, o, storage.OrderPatch{
+						Source:           &address,
+						SourcePoint:      in.Point,
+						ClearSourcePoint: in.Point == nil,
+					})
+				},
+			},
+			{
+				Name:     orderStepTime,
+				Prompt:   i18n.AskTime,
+				Input:    dialog.InputText,
+				Filled:   func(o *models.Order) bool { return o.Time != nil },
+				Validate: b.validatePickupTime,
+				Save: func(ctx context.Context, o *models.Order, in dialog.Input) error {
+					scheduledAt, err := pickuptime.Parse(in.Text, time.Now(), b.location)
+					if err != nil {
+						return fmt.Errorf("pickuptime.Parse: %w", err)
+					}
+					return b.updateOrder(ctx, o, storage.OrderPatch{Time: &in.Text, ScheduledAt: &scheduledAt})
+				},
+			},
+			{
+				Name:      orderStepDestination,
+				Prompt:    i18n.AskDestination,
+				Input:     dialog.InputLocation,
+				Shortcuts: b.placeShortcuts,
+				Filled:    func(o *models.Order) bool { return o.Destination != nil },
+				Save: func(ctx context.Context, o *models.Order, in dialog.Input) error {
+					address := b.addressText(in)
+					return b.updateOrder(ctx, o, storage.OrderPatch{
+						Destination:           &address,
+						DestinationPoint:      in.Point,
+						ClearDestinationPoint: in.Point == nil,
+					})
+				},
+			},
+			{
+				Name:   orderStepPhone,
+				Prompt: i18n.AskPhone,
+				Input:  dialog.InputContact,
+				Filled: func(o *models.Order) bool { return o.Phone != nil },
+				Validate: func(ctx context.Context, _ *models.Order, in dialog.Input) error {
+					return b.validatePhone(ctx, in)
+				},
+				Save: b.savePhone,
+			},
+		},
+		Position: func(o *models.Order) string {
+			if o.FormStep == nil {
+				return ""
+			}
+			return *o.FormStep
+		},
+		SetPosition: func(ctx context.Context, o *models.Order, step string) error {
+			return b.updateOrder(ctx, o, storage.OrderPatch{FormStep: &step})
+		},
+		Finish: b.reviewOrder,
+	}
+}
+
+func (b *Bot) customerConversation(ctx context.Context, chatID int64) dialog.Conversation {
+	return dialog.Conversation{Bot: b.customerBot, ChatID: chatID, Printer: b.printer(ctx)}
+}
+
+// createDraft starts a new order of the user with the patch applied. The
+// phone the user gave last time is filled in as well.
+// errOrderInProgress is returned by createDraft while the user has a placed
+// order that is neither finished nor cancelled. Messages of the customer go
+// to their latest order, so a new draft would cut them off the driver.
+var errOrderInProgress = errors.New("the user has an order in progress")
+
+func (b *Bot) createDraft(ctx context.Context, user *models.User, patch storage.OrderPatch) (*models.Order, error) {
+	active, err := b.store.OrderGetActive(ctx, user.ID)
+	switch {
+	case err == nil && active.Status != models.OrderStatusDraft:
+		return nil, errOrderInProgress
+	case err != nil && !errors.Is(err, storage.ErrNotFound):
+		return nil, fmt.Errorf("store.OrderGetActive: %w", err)
+	}
+	if err = b.resetForms(ctx, user); err != nil {
+		return nil, err
+	}
+	order, err := b.store.OrderCreate(ctx, user.ID, user.TelegramID)
+	if err != nil {
+		return nil, fmt.Errorf("store.OrderCreate: %w", err)
+	}
+	if user.Phone != "" {
+		patch.Phone = &user.Phone
+	}
+	if patch == (storage.OrderPatch{}) {
+		return order, nil
+	}
+	if err = b.updateOrder(ctx, order, patch); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// answerOrderInProgress tells the user why the button did not start a new
+// order.
+func (b *Bot) answerOrderInProgress(ctx context.Context, cb telego.CallbackQuery) error {
+	err := b.customerBot.AnswerCallbackQuery(&telego.AnswerCallbackQueryParams{
+		CallbackQueryID: cb.ID,
+		Text:            b.printer(ctx).T(i18n.OrderInProgress),
+		ShowAlert:       true,
+	})
+	if err != nil {
+		return fmt.Errorf("customerBot.AnswerCallbackQuery: %w", err)
+	}
+	return nil
+}
+
+// updateOrder stores the patch and refreshes the order with the result.
+func (b *Bot) updateOrder(ctx context.Context, o *models.Order, patch storage.OrderPatch) error {
+	updated, err := b.store.OrderUpdate(ctx, o.ID, patch)
+	if err != nil {
+		return fmt.Errorf("store.OrderUpdate: %w", err)
+	}
+	*o = *updated
+	return nil
+}
+
+// addressText is the address stored in the order. It is shown in the drivers
+// chat, so a bare location is described in the language of the chat.
+func (b *Bot) addressText(in dialog.Input) string {
+	if in.Text == "" && in.Point != nil {
+		return b.chatPrinter.T(i18n.PointOnMap, in.Point)
+	}
+	return in.Text
+}
+
+// phoneText is the typed number or the number of a shared contact. Telegram
+// sends contact numbers without the plus sign.
+func phoneText(in dialog.Input) string {
+	if in.Message.Contact != nil && !strings.HasPrefix(in.Text, "+") {
+		return "+" + in.Text
+	}
+	return in.Text
+}
+
+func (b *Bot) validatePhone(ctx context.Context, in dialog.Input) error {
+	if _, err := phone.Normalize(phoneText(in)); err != nil {
+		return dialog.Invalid(b.printer(ctx).T(i18n.PhoneInvalid))
+	}
+	return nil
+}
+
+// savePhone stores the phone in the order and remembers it for the next
+// orders of the customer. The confirmation also removes the contact keyboard.
+func (b *Bot) savePhone(ctx context.Context, o *models.Order, in dialog.Input) error {
+	number, err := phone.Normalize(phoneText(in))
+	if err != nil {
+		return fmt.Errorf("phone.Normalize: %w", err)
+	}
+	if err = b.updateOrder(ctx, o, storage.OrderPatch{Phone: &number}); err != nil {
+		return err
+	}
+	_, err = b.store.UserSetPhone(ctx, o.TelegramID, number)
+	if err != nil {
+		return fmt.Errorf("store.UserSetPhone: %w", err)
+	}
+	_, err = b.customerBot.SendMessage(&telego.SendMessageParams{
+		ChatID:      telego.ChatID{ID: o.TelegramID},
+		Text:        b.printer(ctx).T(i18n.PhoneSaved, number),
+		ReplyMarkup: &telego.ReplyKeyboardRemove{RemoveKeyboard: true},
+	})
+	if err != nil {
+		return fmt.Errorf("customerBot.SendMessage: %w", err)
+	}
+	return nil
+}
+
+func (b *Bot) validatePickupTime(ctx context.Context, _ *models.Order, in dialog.Input) error {
+	_, err := pickuptime.Parse(in.Text, time.Now(), b.location)
+	if err == nil {
+		return nil
+	}
+	p := b.printer(ctx)
+	text := p.T(i18n.TimeUnrecognized)
+	var ambiguousErr *pickuptime.AmbiguousError
+	switch {
+	case errors.As(err, &ambiguousErr) && len(ambiguousErr.Options) > 0:
+		options := make([]string, 0, len(ambiguousErr.Options))
+		for _, option := range ambiguousErr.Options {
+			options = append(options, option.In(b.location).Format(p.T(i18n.TimeOptionLayout)))
+		}
+		text = p.T(i18n.TimeChooseOption, strings.Join(options, p.T(i18n.TimeOptionSeparator)))
+	case errors.As(err, &ambiguousErr):
+		text = p.T(i18n.TimeAmbiguous)
+	case errors.Is(err, pickuptime.ErrInPast):
+		text = p.T(i18n.TimeInPast)
+	}
+	return dialog.Invalid(text)
+}
+
+// reviewOrder prices the filled order and asks the customer to confirm it
+// or change some of the answers.
+func (b *Bot) reviewOrder(ctx context.Context, order *models.Order) error {
+	patch, err := b.pricer.Quote(ctx, order, time.Now())
+	if err != nil {
+		return fmt.Errorf("pricer.Quote: %w", err)
+	}
+	step := orderStepReview
+	patch.FormStep = &step
+	if err = b.updateOrder(ctx, order, patch); err != nil {
+		return err
+	}
+
+	p := b.printer(ctx)
+	edit := func(label i18n.Key, step string) []telego.InlineKeyboardButton {
+		return []telego.InlineKeyboardButton{{
+			Text:         p.T(label),
+			CallbackData: b.callbacks.Encode(callback.EditOrder{OrderID: order.ID, Step: step}),
+		}}
+	}
+	_, err = b.customerBot.SendMessage(&telego.SendMessageParams{
+		ChatID: telego.ChatID{ID: order.TelegramID},
+		Text:   order.ToReview(p, b.location),
+		ReplyMarkup: &telego.InlineKeyboardMarkup{
+			InlineKeyboard: [][]telego.InlineKeyboardButton{
+				edit(i18n.ButtonEditSource, orderStepSource),
+				edit(i18n.ButtonEditDest, orderStepDestination),
+				edit(i18n.ButtonEditTime, orderStepTime),
+				edit(i18n.ButtonEditPhone, orderStepPhone),
+				{{
+					Text:         p.T(i18n.ButtonConfirm),
+					CallbackData: b.callbacks.Encode(callback.ConfirmOrder{OrderID: order.ID}),
+				}},
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("customerBot.SendMessage: %w", err)
+	}
+	return nil
+}
+
+func (b *Bot) HandleEditOrder(ctx context.Context, cb telego.CallbackQuery, data callback.EditOrder) error {
+	order, err := b.draftOrder(ctx, cb, data.OrderID)
+	if err != nil || order == nil {
+		return err
+	}
+	return b.orderForm.Goto(ctx, b.customerConversation(ctx, cb.From.ID), order, data.Step)
+}
+
+func (b *Bot) HandleConfirmOrder(ctx context.Context, cb telego.CallbackQuery, data callback.ConfirmOrder) error {
+	order, err := b.draftOrder(ctx, cb, data.OrderID)
+	if err != nil || order == nil {
+		return err
+	}
+	if order.FormStep == nil || *order.FormStep != orderStepReview {
+		// The customer is changing an answer, so the card is outdated.
+		return b.reviewOrder(ctx, order)
+	}
+	if cb.Message != nil {
+		_, err = b.customerBot.EditMessageReplyMarkup(&telego.EditMessageReplyMarkupParams{
+			ChatID:    telego.ChatID{ID: cb.Message.Chat.ID},
+			MessageID: cb.Message.MessageID,
+		})
+		if err != nil {
+			b.log(ctx).Errorf("customerBot.EditMessageReplyMarkup: %v", err)
+		}
+	}
+	return b.placeOrder(ctx, order)
+}
+
+// draftOrder returns the customer's order that is still being filled in. If
+// the order is already placed it answers the callback and returns nil.
+func (b *Bot) draftOrder(ctx context.Context, cb telego.CallbackQuery, orderID int) (*models.Order, error) {
+	user, err := b.store.UserGet(ctx, cb.From.ID)
+	if err != nil {
+		return nil, fmt.Errorf("store.UserGet: %w", err)
+	}
+	order, err := b.store.OrderGetByID(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("store.OrderGetByID(%d): %w", orderID, err)
+	}
+	if order.UserID == nil || *order.UserID != user.ID {
+		return nil, fmt.Errorf("user %s tried to change order %d of another user", user.ID, orderID)
+	}
+
+	params := &telego.AnswerCallbackQueryParams{CallbackQueryID: cb.ID}
+	if order.Status != models.OrderStatusDraft {
+		p := b.printer(ctx)
+		params.Text = p.T(i18n.ActionUnavailable, p.T(orderStatusTitles[order.Status]))
+		params.ShowAlert = true
+	}
+	err = b.customerBot.AnswerCallbackQuery(params)
+	if err != nil {
+		b.log(ctx).Errorf("customerBot.AnswerCallbackQuery: %v", err)
+	}
+	if order.Status != models.OrderStatusDraft {
+		return nil, nil
+	}
+	return order, nil
+}
+
+// placeOrder sends the confirmed order to the drivers chat.
+func (b *Bot) placeOrder(ctx context.Context, order *models.Order) error {
+	order, err := b.store.OrderSetStatus(ctx, order.ID, models.OrderStatusAwaitingDriver, order.TelegramID)
+	if err != nil {
+		return fmt.Errorf("store.OrderSetStatus: %w", err)
+	}
+
+	p := b.printer(ctx)
+	_, err = b.customerBot.SendMessage(&telego.SendMessageParams{
+		ChatID:      telego.ChatID{ID: order.TelegramID},
+		Text:        p.T(i18n.OrderAwaitingDriver, *order.Price),
+		ReplyMarkup: b.cancelOrderKeyboard(p, order.ID),
+	})
+	if err != nil {
+		return fmt.Errorf("customerBot.SendMessage: %w", err)
+	}
+	post, err := b.driverBot.SendMessage(&telego.SendMessageParams{
+		ChatID: telego.ChatID{ID: b.driversChatID},
+		Text:   order.ToDriverChat(b.chatPrinter, b.location),
+		ReplyMarkup: &telego.InlineKeyboardMarkup{
+			InlineKeyboard: [][]telego.InlineKeyboardButton{
+				{
+					{
+						Text:         b.chatPrinter.T(i18n.ButtonTakeOrder),
+						CallbackData: b.callbacks.Encode(callback.TakeOrder{OrderID: order.ID}),
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("driverBot.SendMessage: %w", err)
+	}
+	_, err = b.store.OrderUpdate(ctx, order.ID, storage.OrderPatch{DriversChatMessageID: &post.MessageID})
+	if err != nil {
+		return fmt.Errorf("store.OrderUpdate: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mymmrac/telego"
+
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/callback"
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/dialog"
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/i18n"
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/models"
+)
+
+// newPlaceForm builds the form that names an address the customer saves
+// after a trip.
+func (b *Bot) newPlaceForm() *dialog.Flow[*models.Place] {
+	return &dialog.Flow[*models.Place]{
+		Steps: []dialog.Step[*models.Place]{
+			{
+				Name:     "name",
+				Prompt:   i18n.AskPlaceName,
+				Input:    dialog.InputText,
+				Optional: true,
+				Shortcuts: func(ctx context.Context, _ *models.Place) []dialog.Shortcut {
+					p := b.printer(ctx)
+					shortcuts := make([]dialog.Shortcut, 0, 2)
+					for _, name := range []i18n.Key{i18n.PlaceHome, i18n.PlaceWork} {
+						shortcuts = append(shortcuts, dialog.Shortcut{Label: p.T(name), Input: dialog.Input{Text: p.T(name)}})
+					}
+					return shortcuts
+				},
+				Filled: func(p *models.Place) bool { return p.Name != "" },
+				Save: func(ctx context.Context, p *models.Place, in dialog.Input) error {
+					updated, err := b.store.PlaceSetName(ctx, p.ID, in.Text)
+					if err != nil {
+						return fmt.Errorf("store.PlaceSetName: %w", err)
+					}
+					*p = *updated
+					return nil
+				},
+			},
+		},
+		// The form has one step and a place without a name is at it.
+		Position:    func(*models.Place) string { return "" },
+		SetPosition: func(context.Context, *models.Place, string) error { return nil },
+		Finish:      b.finishPlace,
+	}
+}
+
+func (b *Bot) finishPlace(ctx context.Context, place *models.Place) error {
+	user, err := b.store.UserGetByID(ctx, place.UserID)
+	if err != nil {
+		return fmt.Errorf("store.UserGetByID: %w", err)
+	}
+	p := b.printer(ctx)
+	text := p.T(i18n.PlaceSaved, place.Name)
+	if place.Name == "" {
+		// The name was skipped.
+		if err = b.store.PlaceDeleteUnnamed(ctx, place.UserID); err != nil {
+			return fmt.Errorf("store.PlaceDeleteUnnamed: %w", err)
+		}
+		text = p.T(i18n.PlaceNotSaved)
+	}
+	_, err = b.customerBot.SendMessage(&telego.SendMessageParams{
+		ChatID:      telego.ChatID{ID: user.TelegramID},
+		Text:        text,
+		ReplyMarkup: &telego.ReplyKeyboardRemove{RemoveKeyboard: true},
+	})
+	if err != nil {
+		return fmt.Errorf("customerBot.SendMessage: %w", err)
+	}
+	return nil
+}
+
+// placeShortcuts offers the saved places of the customer as answers to the
+// address steps of the order form.
+func (b *Bot) placeShortcuts(ctx context.Context, o *models.Order) []dialog.Shortcut {
+	if o.UserID == nil {
+		return nil
+	}
+	places, err := b.store.PlaceList(ctx, *o.UserID)
+	if err != nil {
+		b.log(ctx).Errorf("store.PlaceList: %v", err)
+		return nil
+	}
+	shortcuts := make([]dialog.Shortcut, 0, len(places))
+	for _, place := range places {
+		shortcuts = append(shortcuts, dialog.Shortcut{
+			Label: place.Name,
+			Input: dialog.Input{Text: place.Address, Point: place.Point},
+		})
+	}
+	return shortcuts
+}
+
+// offerPlaces offers the customer to save the addresses of the finished
+// order that are not saved yet.
+func (b *Bot) offerPlaces(ctx context.Context, p i18n.Printer, order *models.Order) {
+	if order.UserID == nil || order.Source == nil || order.Destination == nil {
+		return
+	}
+	places, err := b.store.PlaceList(ctx, *order.UserID)
+	if err != nil {
+		b.log(ctx).Errorf("store.PlaceList: %v", err)
+		return
+	}
+	saved := make(map[string]bool, len(places))
+	for _, place := range places {
+		saved[place.Address] = true
+	}
+
+	var rows [][]telego.InlineKeyboardButton
+	addresses := []struct {
+		step    string
+		address string
+	}{
+		{orderStepSource, *order.Source},
+		{orderStepDestination, *order.Destination},
+	}
+	for _, a := range addresses {
+		if saved[a.address] {
+			continue
+		}
+		saved[a.address] = true
+		rows = append(rows, []telego.InlineKeyboardButton{{
+			Text:         p.T(i18n.ButtonSavePlace, a.address),
+			CallbackData: b.callbacks.Encode(callback.SavePlace{OrderID: order.ID, Step: a.step}),
+		}})
+	}
+	if len(rows) == 0 {
+		return
+	}
+	_, err = b.customerBot.SendMessage(&telego.SendMessageParams{
+		ChatID:      telego.ChatID{ID: order.TelegramID},
+		Text:        p.T(i18n.SavePlaces),
+		ReplyMarkup: &telego.InlineKeyboardMarkup{InlineKeyboard: rows},
+	})
+	if err != nil {
+		b.log(ctx).Errorf("customerBot.SendMessage: %v", err)
+	}
+}
+
+func (b *Bot) HandleSavePlace(ctx context.Context, cb telego.CallbackQuery, data callback.SavePlace) error {
+	user, err := b.store.UserGet(ctx, cb.From.ID)
+	if err != nil {
+		return fmt.Errorf("store.UserGet: %w", err)
+	}
+	order, err := b.store.OrderGetByID(ctx, data.OrderID)
+	if err != nil {
+		return fmt.Errorf("store.OrderGetByID(%d): %w", data.OrderID, err)
+	}
+	if order.UserID == nil || *order.UserID != user.ID {
+		return fmt.Errorf("user %s tried to save an address of order %d of another user", user.ID, order.ID)
+	}
+	address, point := order.Source, order.SourcePoint
+	if data.Step == orderStepDestination {
+		address, point = order.Destination, order.DestinationPoint
+	}
+	if address == nil {
+		return fmt.Errorf("order %d has no %s address", order.ID, data.Step)
+	}
+
+	err = b.customerBot.AnswerCallbackQuery(&telego.AnswerCallbackQueryParams{CallbackQueryID: cb.ID})
+	if err != nil {
+		b.log(ctx).Errorf("customerBot.AnswerCallbackQuery: %v", err)
+	}
+	if err = b.resetForms(ctx, user); err != nil {
+		return err
+	}
+	place, err := b.store.PlaceCreate(ctx, user.ID, *address, point)
+	if err != nil {
+		return fmt.Errorf("store.PlaceCreate: %w", err)
+	}
+	return b.placeForm.Start(ctx, b.customerConversation(ctx, user.TelegramID), place)
+}
@@ -0,0 +1,224 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mymmrac/telego"
+
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/callback"
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/dialog"
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/i18n"
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/models"
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/phone"
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/storage"
+)
+
+const (
+	profileStepName  = "name"
+	profileStepPhone = "phone"
+)
+
+// newProfileForm builds the form that changes the name and the default
+// phone of a customer. It is only entered with the buttons of /profile.
+func (b *Bot) newProfileForm() *dialog.Flow[*models.User] {
+	return &dialog.Flow[*models.User]{
+		Steps: []dialog.Step[*models.User]{
+			{
+				Name:     profileStepName,
+				Prompt:   i18n.AskName,
+				Input:    dialog.InputText,
+				Optional: true,
+				Filled:   func(u *models.User) bool { return u.Name != "" },
+				Save: func(ctx context.Context, u *models.User, in dialog.Input) error {
+					updated, err := b.store.UserSetName(ctx, u.TelegramID, in.Text)
+					if err != nil {
+						return fmt.Errorf("store.UserSetName: %w", err)
+					}
+					*u = *updated
+					return nil
+				},
+			},
+			{
+				Name:     profileStepPhone,
+				Prompt:   i18n.AskPhone,
+				Input:    dialog.InputContact,
+				Optional: true,
+				Filled:   func(u *models.User) bool { return u.Phone != "" },
+				Validate: func(ctx context.Context, _ *models.User, in dialog.Input) error {
+					return b.validatePhone(ctx, in)
+				},
+				Save: func(ctx context.Context, u *models.User, in dialog.Input) error {
+					number, err := phone.Normalize(phoneText(in))
+					if err != nil {
+						return fmt.Errorf("phone.Normalize: %w", err)
+					}
+					updated, err := b.store.UserSetPhone(ctx, u.TelegramID, number)
+					if err != nil {
+						return fmt.Errorf("store.UserSetPhone: %w", err)
+					}
+					*u = *updated
+					return nil
+				},
+			},
+		},
+		Position: func(u *models.User) string { return u.FormStep },
+		SetPosition: func(ctx context.Context, u *models.User, step string) error {
+			return b.setProfileStep(ctx, u, step)
+		},
+		Finish: b.finishProfile,
+	}
+}
+
+func (b *Bot) setProfileStep(ctx context.Context, u *models.User, step string) error {
+	updated, err := b.store.UserSetFormStep(ctx, u.TelegramID, step)
+	if err != nil {
+		return fmt.Errorf("store.UserSetFormStep: %w", err)
+	}
+	*u = *updated
+	return nil
+}
+
+func (b *Bot) finishProfile(ctx context.Context, u *models.User) error {
+	if err := b.setProfileStep(ctx, u, ""); err != nil {
+		return err
+	}
+	_, err := b.customerBot.SendMessage(&telego.SendMessageParams{
+		ChatID:      telego.ChatID{ID: u.TelegramID},
+		Text:        b.printer(ctx).T(i18n.ProfileSaved),
+		ReplyMarkup: &telego.ReplyKeyboardRemove{RemoveKeyboard: true},
+	})
+	if err != nil {
+		return fmt.Errorf("customerBot.SendMessage: %w", err)
+	}
+	return b.sendProfile(ctx, u)
+}
+
+// resetForms drops the profile form and the naming of a place the user has
+// not finished, so that their next message goes to the form they start.
+func (b *Bot) resetForms(ctx context.Context, u *models.User) error {
+	if u.FormStep != "" {
+		if err := b.setProfileStep(ctx, u, ""); err != nil {
+			return err
+		}
+	}
+	if err := b.store.PlaceDeleteUnnamed(ctx, u.ID); err != nil {
+		return fmt.Errorf("store.PlaceDeleteUnnamed: %w", err)
+	}
+	return nil
+}
+
+func (b *Bot) HandleProfileCommand(ctx context.Context, update telego.Update) error {
+	user, err := b.store.UserGet(ctx, update.Message.From.ID)
+	if err != nil {
+		return fmt.Errorf("store.UserGet: %w", err)
+	}
+	return b.sendProfile(ctx, user)
+}
+
+func (b *Bot) sendProfile(ctx context.Context, user *models.User) error {
+	text, markup, err := b.profileCard(ctx, user)
+	if err != nil {
+		return err
+	}
+	_, err = b.customerBot.SendMessage(&telego.SendMessageParams{
+		ChatID:      telego.ChatID{ID: user.TelegramID},
+		Text:        text,
+		ReplyMarkup: markup,
+	})
+	if err != nil {
+		return fmt.Errorf("customerBot.SendMessage: %w", err)
+	}
+	return nil
+}
+
+func (b *Bot) profileCard(ctx context.Context, user *models.User) (string, *telego.InlineKeyboardMarkup, error) {
+	places, err := b.store.PlaceList(ctx, user.ID)
+	if err != nil {
+		return "", nil, fmt.Errorf("store.PlaceList: %w", err)
+	}
+	p := b.printer(ctx)
+	orNotSet := func(s string) string {
+		if s == "" {
+			return p.T(i18n.ProfileNotSet)
+		}
+		return s
+	}
+	edit := func(label i18n.Key, step string) []telego.InlineKeyboardButton {
+		return []telego.InlineKeyboardButton{{
+			Text:         p.T(label),
+			CallbackData: b.callbacks.Encode(callback.EditProfile{Step: step}),
+		}}
+	}
+
+	texts := []string{p.T(i18n.ProfileCard, orNotSet(user.Name), orNotSet(user.Phone))}
+	rows := [][]telego.InlineKeyboardButton{
+		edit(i18n.ButtonEditName, profileStepName),
+		edit(i18n.ButtonEditPhone, profileStepPhone),
+	}
+	if len(places) == 0 {
+		texts = append(texts, p.T(i18n.NoPlaces))
+	} else {
+		lines := []string{p.T(i18n.ProfilePlaces)}
+		for _, place := range places {
+			lines = append(lines, p.T(i18n.ProfilePlace, place.Name, place.Address))
+			rows = append(rows, []telego.InlineKeyboardButton{{
+				Text:         p.T(i18n.ButtonDeletePlace, place.Name),
+				CallbackData: b.callbacks.Encode(callback.DeletePlace{PlaceID: place.ID}),
+			}})
+		}
+		texts = append(texts, strings.Join(lines, "\n"))
+	}
+	return strings.Join(texts, "\n\n"), &telego.InlineKeyboardMarkup{InlineKeyboard: rows}, nil
+}
+
+func (b *Bot) HandleEditProfile(ctx context.Context, cb telego.CallbackQuery, data callback.EditProfile) error {
+	user, err := b.store.UserGet(ctx, cb.From.ID)
+	if err != nil {
+		return fmt.Errorf("store.UserGet: %w", err)
+	}
+	err = b.customerBot.AnswerCallbackQuery(&telego.AnswerCallbackQueryParams{CallbackQueryID: cb.ID})
+	if err != nil {
+		b.log(ctx).Errorf("customerBot.AnswerCallbackQuery: %v", err)
+	}
+	if err = b.resetForms(ctx, user); err != nil {
+		return err
+	}
+	return b.profileForm.Goto(ctx, b.customerConversation(ctx, user.TelegramID), user, data.Step)
+}
+
+func (b *Bot) HandleDeletePlace(ctx context.Context, cb telego.CallbackQuery, data callback.DeletePlace) error {
+	user, err := b.store.UserGet(ctx, cb.From.ID)
+	if err != nil {
+		return fmt.Errorf("store.UserGet: %w", err)
+	}
+	// A place deleted by an earlier press is not an error, the card is just
+	// refreshed.
+	err = b.store.PlaceDelete(ctx, user.ID, data.PlaceID)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return fmt.Errorf("store.PlaceDelete: %w", err)
+	}
+	err = b.customerBot.AnswerCallbackQuery(&telego.AnswerCallbackQueryParams{CallbackQueryID: cb.ID})
+	if err != nil {
+		b.log(ctx).Errorf("customerBot.AnswerCallbackQuery: %v", err)
+	}
+	if cb.Message == nil {
+		return nil
+	}
+	text, markup, err := b.profileCard(ctx, user)
+	if err != nil {
+		return err
+	}
+	_, err = b.customerBot.EditMessageText(&telego.EditMessageTextParams{
+		ChatID:      telego.ChatID{ID: cb.Message.Chat.ID},
+		MessageID:   cb.Message.MessageID,
+		Text:        text,
+		ReplyMarkup: markup,
+	})
+	if err != nil {
+		return fmt.Errorf("customerBot.EditMessageText: %w", err)
+	}
+	return nil
+}
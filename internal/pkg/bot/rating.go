@@ -0,0 +1,191 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mymmrac/telego"
+
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/callback"
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/dialog"
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/i18n"
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/models"
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/storage"
+)
+
+// lowScore and lower ratings are sent to the admin chat.
+const lowScore = 2
+
+func (b *Bot) ratingKeyboard(orderID int) *telego.InlineKeyboardMarkup {
+	buttons := make([]telego.InlineKeyboardButton, 0, models.MaxScore)
+	for score := models.MinScore; score <= models.MaxScore; score++ {
+		buttons = append(buttons, telego.InlineKeyboardButton{
+			Text:         strconv.Itoa(score) + " ★",
+			CallbackData: b.callbacks.Encode(callback.RateOrder{OrderID: orderID, Score: score}),
+		})
+	}
+	return &telego.InlineKeyboardMarkup{InlineKeyboard: [][]telego.InlineKeyboardButton{buttons}}
+}
+
+// newFeedbackForm builds the form that asks for a comment after the
+// customer rated the trip.
+func (b *Bot) newFeedbackForm() *dialog.Flow[*models.Rating] {
+	return &dialog.Flow[*models.Rating]{
+		Steps: []dialog.Step[*models.Rating]{
+			{
+				Name:     "comment",
+				Prompt:   i18n.AskComment,
+				Input:    dialog.InputText,
+				Optional: true,
+				Filled:   func(r *models.Rating) bool { return r.Comment != nil },
+				Save: func(ctx context.Context, r *models.Rating, in dialog.Input) error {
+					return b.saveComment(ctx, r, in.Text)
+				},
+			},
+		},
+		// The form has one step and a rating without a comment is at it.
+		Position:    func(*models.Rating) string { return "" },
+		SetPosition: func(context.Context, *models.Rating, string) error { return nil },
+		Finish:      b.finishFeedback,
+	}
+}
+
+func (b *Bot) HandleRateOrder(ctx context.Context, cb telego.CallbackQuery, data callback.RateOrder) error {
+	if data.Score < models.MinScore || data.Score > models.MaxScore {
+		return fmt.Errorf("rating score %d is out of range", data.Score)
+	}
+	user, err := b.store.UserGet(ctx, cb.From.ID)
+	if err != nil {
+		return fmt.Errorf("store.UserGet: %w", err)
+	}
+	order, err := b.store.OrderGetByID(ctx, data.OrderID)
+	if err != nil {
+		return fmt.Errorf("store.OrderGetByID(%d): %w", data.OrderID, err)
+	}
+	if order.UserID == nil || *order.UserID != user.ID {
+		return fmt.Errorf("user %s tried to rate order %d of another user", user.ID, order.ID)
+	}
+
+	p := b.printer(ctx)
+	if order.Status != models.OrderStatusFinished || order.DriverTelegramID == nil {
+		err = b.customerBot.AnswerCallbackQuery(&telego.AnswerCallbackQueryParams{
+			CallbackQueryID: cb.ID,
+			Text:            p.T(i18n.TripNotFinished),
+			ShowAlert:       true,
+		})
+		if err != nil {
+			return fmt.Errorf("customerBot.AnswerCallbackQuery: %w", err)
+		}
+		return nil
+	}
+	rating, err := b.store.RatingCreate(ctx, order.ID, *order.DriverTelegramID, data.Score)
+	if errors.Is(err, storage.ErrAlreadyRated) {
+		err = b.customerBot.AnswerCallbackQuery(&telego.AnswerCallbackQueryParams{
+			CallbackQueryID: cb.ID,
+			Text:            p.T(i18n.AlreadyRated),
+		})
+		if err != nil {
+			return fmt.Errorf("customerBot.AnswerCallbackQuery: %w", err)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("store.RatingCreate: %w", err)
+	}
+
+	err = b.customerBot.AnswerCallbackQuery(&telego.AnswerCallbackQueryParams{CallbackQueryID: cb.ID})
+	if err != nil {
+		b.log(ctx).Errorf("customerBot.AnswerCallbackQuery: %v", err)
+	}
+	if cb.Message != nil {
+		_, err = b.customerBot.EditMessageText(&telego.EditMessageTextParams{
+			ChatID:    telego.ChatID{ID: cb.Message.Chat.ID},
+			MessageID: cb.Message.MessageID,
+			Text:      cb.Message.Text + "\n" + strings.Repeat("★", rating.Score),
+		})
+		if err != nil {
+			b.log(ctx).Errorf("customerBot.EditMessageText: %v", err)
+		}
+	}
+	if rating.Score <= lowScore {
+		b.sendAdmin(ctx, b.chatPrinter.T(i18n.LowRating, rating.Score, rating.OrderID, rating.DriverTelegramID))
+	}
+	return b.feedbackForm.Start(ctx, b.customerConversation(ctx, cb.From.ID), rating)
+}
+
+// handleFeedback takes a message of a customer whose last order is finished
+// as the comment to its rating.
+func (b *Bot) handleFeedback(ctx context.Context, order *models.Order, message *telego.Message) error {
+	rating, err := b.store.RatingGet(ctx, order.ID)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("store.RatingGet: %w", err)
+	}
+	if rating.Comment != nil {
+		return nil
+	}
+	return b.feedbackForm.Handle(ctx, b.customerConversation(ctx, order.TelegramID), rating, message)
+}
+
+func (b *Bot) saveComment(ctx context.Context, r *models.Rating, comment string) error {
+	updated, err := b.store.RatingSetComment(ctx, r.OrderID, comment)
+	if err != nil {
+		return fmt.Errorf("store.RatingSetComment: %w", err)
+	}
+	*r = *updated
+	return nil
+}
+
+func (b *Bot) finishFeedback(ctx context.Context, r *models.Rating) error {
+	if r.Comment == nil {
+		// The comment was skipped.
+		if err := b.saveComment(ctx, r, ""); err != nil {
+			return err
+		}
+	}
+	if r.Score <= lowScore && *r.Comment != "" {
+		b.sendAdmin(ctx, b.chatPrinter.T(i18n.LowRatingComment, r.OrderID, *r.Comment))
+	}
+	order, err := b.store.OrderGetByID(ctx, r.OrderID)
+	if err != nil {
+		return fmt.Errorf("store.OrderGetByID(%d): %w", r.OrderID, err)
+	}
+	_, err = b.customerBot.SendMessage(&telego.SendMessageParams{
+		ChatID:      telego.ChatID{ID: order.TelegramID},
+		Text:        b.printer(ctx).T(i18n.FeedbackThanks),
+		ReplyMarkup: &telego.ReplyKeyboardRemove{RemoveKeyboard: true},
+	})
+	if err != nil {
+		return fmt.Errorf("customerBot.SendMessage: %w", err)
+	}
+	return nil
+}
+
+func (b *Bot) sendAdmin(ctx context.Context, text string) {
+	_, err := b.driverBot.SendMessage(&telego.SendMessageParams{
+		ChatID: telego.ChatID{ID: b.adminChatID},
+		Text:   text,
+	})
+	if err != nil {
+		b.log(ctx).Errorf("driverBot.SendMessage: %v", err)
+	}
+}
+
+// driverRatingText is shown to the customer when the driver takes the order.
+// It is empty for drivers without ratings.
+func (b *Bot) driverRatingText(ctx context.Context, p i18n.Printer, driverTelegramID int64) string {
+	rating, err := b.store.DriverRating(ctx, driverTelegramID)
+	if err != nil {
+		b.log(ctx).Errorf("store.DriverRating: %v", err)
+		return ""
+	}
+	if rating.Count == 0 {
+		return ""
+	}
+	return p.N(i18n.DriverRating, rating.Count, rating.Average, rating.Count)
+}
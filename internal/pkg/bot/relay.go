@@ -0,0 +1,234 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/mymmrac/telego"
+	"github.com/mymmrac/telego/telegoutil"
+
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/i18n"
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/models"
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/storage"
+)
+
+// fileClient downloads the photos relayed between the bots.
+var fileClient = &http.Client{Timeout: 30 * time.Second}
+
+// relayMessage is what gets logged for every relayed message.
+type relayMessage struct {
+	From      string   `json:"from"`
+	Kind      string   `json:"kind"`
+	Text      string   `json:"text,omitempty"`
+	FileID    string   `json:"file_id,omitempty"`
+	Latitude  *float64 `json:"latitude,omitempty"`
+	Longitude *float64 `json:"longitude,omitempty"`
+}
+
+// relayOpen reports whether the customer and the driver of the order can
+// write to each other. The relay closes once the order is finished or
+// cancelled.
+func relayOpen(order *models.Order) bool {
+	if order.DriverTelegramID == nil || order.TelegramID == 0 {
+		return false
+	}
+	switch order.Status {
+	case models.OrderStatusAssigned, models.OrderStatusDriverArrived, models.OrderStatusInProgress:
+		return true
+	}
+	return false
+}
+
+func (b *Bot) relayFromCustomer(ctx context.Context, order *models.Order, message *telego.Message) error {
+	err := b.relay(
+		ctx,
+		order,
+		message,
+		b.customerBot,
+		b.driverBot,
+		*order.DriverTelegramID,
+		"customer",
+		b.printerFor(ctx, *order.DriverTelegramID).T(i18n.MessageFromCustomer, order.ID),
+	)
+	if err != nil {
+		return fmt.Errorf("relay to driver: %w", err)
+	}
+	return nil
+}
+
+// HandleDriverRelay forwards a private message of the driver to the customer
+// of the driver's active order.
+func (b *Bot) HandleDriverRelay(ctx context.Context, update telego.Update) error {
+	message := update.Message
+	order, err := b.store.OrderGetActiveByDriver(ctx, message.From.ID)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return fmt.Errorf("store.OrderGetActiveByDriver: %w", err)
+	}
+
+	p := b.printer(ctx)
+	text := ""
+	switch {
+	case order == nil:
+		text = p.T(i18n.DriverNoActiveOrder)
+	case !relayOpen(order):
+		text = p.T(i18n.RelayClosed)
+	}
+	if text != "" {
+		_, err = b.driverBot.SendMessage(&telego.SendMessageParams{
+			ChatID: telego.ChatID{ID: message.From.ID},
+			Text:   text,
+		})
+		if err != nil {
+			return fmt.Errorf("driverBot.SendMessage: %w", err)
+		}
+		return nil
+	}
+
+	err = b.relay(
+		ctx,
+		order,
+		message,
+		b.driverBot,
+		b.customerBot,
+		order.TelegramID,
+		"driver",
+		b.printerFor(ctx, order.TelegramID).T(i18n.MessageFromDriver),
+	)
+	if err != nil {
+		return fmt.Errorf("relay to customer: %w", err)
+	}
+	return nil
+}
+
+func (b *Bot) relay(
+	ctx context.Context,
+	order *models.Order,
+	message *telego.Message,
+	from *telego.Bot,
+	to *telego.Bot,
+	chatID int64,
+	sender string,
+	title string,
+) error {
+	logged := relayMessage{From: sender}
+	var err error
+	switch {
+	case message.Location != nil:
+		logged.Kind = "location"
+		logged.Latitude = &message.Location.Latitude
+		logged.Longitude = &message.Location.Longitude
+		_, err = to.SendMessage(&telego.SendMessageParams{
+			ChatID: telego.ChatID{ID: chatID},
+			Text:   title + ":",
+		})
+		if err != nil {
+			return fmt.Errorf("SendMessage: %w", err)
+		}
+		_, err = to.SendLocation(&telego.SendLocationParams{
+			ChatID:    telego.ChatID{ID: chatID},
+			Latitude:  message.Location.Latitude,
+			Longitude: message.Location.Longitude,
+		})
+		if err != nil {
+			return fmt.Errorf("SendLocation: %w", err)
+		}
+	case len(message.Photo) != 0:
+		logged.Kind = "photo"
+		logged.Text = message.Caption
+		logged.FileID = message.Photo[len(message.Photo)-1].FileID
+		caption := title
+		if message.Caption != "" {
+			caption += ":\n" + message.Caption
+		}
+		if err = b.relayPhoto(ctx, from, to, chatID, logged.FileID, caption); err != nil {
+			return err
+		}
+	case message.Text != "":
+		logged.Kind = "text"
+		logged.Text = message.Text
+		_, err = to.SendMessage(&telego.SendMessageParams{
+			ChatID: telego.ChatID{ID: chatID},
+			Text:   fmt.Sprintf("%s:\n%s", title, message.Text),
+		})
+		if err != nil {
+			return fmt.Errorf("SendMessage: %w", err)
+		}
+	default:
+		_, err = from.SendMessage(&telego.SendMessageParams{
+			ChatID: telego.ChatID{ID: message.From.ID},
+			Text:   b.printer(ctx).T(i18n.RelayUnsupported),
+		})
+		if err != nil {
+			return fmt.Errorf("SendMessage: %w", err)
+		}
+		return nil
+	}
+
+	payload, err := json.Marshal(logged)
+	if err != nil {
+		return fmt.Errorf("json.Marshal: %w", err)
+	}
+	err = b.store.OrderAddEvent(ctx, order.ID, models.OrderEventMessage, message.From.ID, payload)
+	if err != nil {
+		return fmt.Errorf("store.OrderAddEvent: %w", err)
+	}
+	return nil
+}
+
+// relayPhoto re-uploads the photo, because file IDs of one bot are not valid
+// for the other.
+func (b *Bot) relayPhoto(
+	ctx context.Context,
+	from *telego.Bot,
+	to *telego.Bot,
+	chatID int64,
+	fileID string,
+	caption string,
+) error {
+	file, err := from.GetFile(&telego.GetFileParams{FileID: fileID})
+	if err != nil {
+		return fmt.Errorf("GetFile: %w", err)
+	}
+	request, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodGet,
+		fmt.Sprintf("https://api.telegram.org/file/bot%s/%s", from.Token(), file.FilePath),
+		nil,
+	)
+	if err != nil {
+		return fmt.Errorf("http.NewRequestWithContext: %w", withoutURL(err))
+	}
+	response, err := fileClient.Do(request)
+	if err != nil {
+		return fmt.Errorf("fileClient.Do: %w", withoutURL(err))
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("fileClient.Do: returned status %d", response.StatusCode)
+	}
+
+	_, err = to.SendPhoto(&telego.SendPhotoParams{
+		ChatID:  telego.ChatID{ID: chatID},
+		Photo:   telegoutil.File(telegoutil.NameReader(response.Body, "photo.jpg")),
+		Caption: caption,
+	})
+	if err != nil {
+		return fmt.Errorf("SendPhoto: %w", err)
+	}
+	return nil
+}
+
+// withoutURL drops the URL of a failed download from the error, as the URL
+// has the bot token in it.
+func withoutURL(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Err
+	}
+	return err
+}
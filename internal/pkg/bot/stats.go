@@ -0,0 +1,90 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mymmrac/telego"
+
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/earnings"
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/i18n"
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/storage"
+)
+
+// HandleStatsCommand sends the driver their earnings. The reply always goes
+// to the private chat, so /stats in the drivers chat does not show them to
+// everyone.
+func (b *Bot) HandleStatsCommand(ctx context.Context, update telego.Update) error {
+	driverTelegramID := update.Message.From.ID
+	p := b.printer(ctx)
+
+	_, err := b.store.DriverGetByTelegramID(ctx, driverTelegramID)
+	if errors.Is(err, storage.ErrNotFound) {
+		return b.sendDriver(driverTelegramID, p.T(i18n.StatsOnlyDrivers))
+	}
+	if err != nil {
+		return fmt.Errorf("store.DriverGetByTelegramID: %w", err)
+	}
+
+	periods := earnings.PeriodsAt(time.Now(), b.location)
+	trips, err := b.store.DriverTrips(ctx, driverTelegramID, periods.Since())
+	if err != nil {
+		return fmt.Errorf("store.DriverTrips: %w", err)
+	}
+	report := earnings.Summarize(trips, periods, b.commissionPercent)
+
+	sections := []struct {
+		title   i18n.Key
+		summary earnings.Summary
+	}{
+		{i18n.StatsToday, report.Today},
+		{i18n.StatsWeek, report.Week},
+		{i18n.StatsMonth, report.Month},
+	}
+	texts := make([]string, 0, len(sections))
+	for _, section := range sections {
+		s := section.summary
+		texts = append(texts, strings.Join([]string{
+			p.T(section.title) + ":",
+			p.N(i18n.StatsOrders, s.Orders, s.Orders),
+			p.T(i18n.StatsDriving, int(s.Driving.Hours()), int(s.Driving.Minutes())%60),
+			p.T(i18n.StatsMoney, s.Gross, s.Commission, s.Unpaid),
+		}, "\n"))
+	}
+	return b.sendDriver(driverTelegramID, strings.Join(texts, "\n\n"))
+}
+
+// chargeCommission bills the driver the commission of the finished order and
+// returns the line with the payment link, or "" if there is nothing to pay.
+// A failed charge is only logged: /stats keeps showing it as unpaid.
+func (b *Bot) chargeCommission(ctx context.Context, p i18n.Printer, orderID int) string {
+	trip, err := b.store.DriverTripGet(ctx, orderID)
+	if err != nil {
+		b.log(ctx).Errorf("store.DriverTripGet(%d): %v", orderID, err)
+		return ""
+	}
+	commission := earnings.Commission(trip.Fare(), b.commissionPercent)
+	if commission == 0 {
+		return ""
+	}
+	url, err := b.processor.ChargeCommission(ctx, p, orderID, commission)
+	if err != nil {
+		b.log(ctx).Errorf("processor.ChargeCommission(%d): %v", orderID, err)
+		return ""
+	}
+	return p.T(i18n.CommissionToPay, commission, url)
+}
+
+func (b *Bot) sendDriver(chatID int64, text string) error {
+	_, err := b.driverBot.SendMessage(&telego.SendMessageParams{
+		ChatID: telego.ChatID{ID: chatID},
+		Text:   text,
+	})
+	if err != nil {
+		return fmt.Errorf("driverBot.SendMessage: %w", err)
+	}
+	return nil
+}
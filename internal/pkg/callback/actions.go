@@ -0,0 +1,197 @@
+package callback
+
+const (
+	ActionCreateOrder   Action = "createOrder"
+	ActionTakeOrder     Action = "order"
+	ActionArrived       Action = "arrived"
+	ActionStarted       Action = "started"
+	ActionFinished      Action = "finished"
+	ActionCancelOrder   Action = "cancelOrder"
+	ActionApproveDriver Action = "approveDriver"
+	ActionRejectDriver  Action = "rejectDriver"
+	ActionSetLanguage   Action = "setLanguage"
+	ActionEditOrder     Action = "editOrder"
+	ActionConfirmOrder  Action = "confirmOrder"
+	ActionRateOrder     Action = "rateOrder"
+	ActionOrderHistory  Action = "orders"
+	ActionRepeatOrder   Action = "repeatOrder"
+	ActionEditProfile   Action = "editProfile"
+	ActionSavePlace     Action = "savePlace"
+	ActionDeletePlace   Action = "deletePlace"
+)
+
+var decoders = map[Action]func(args []string) (Data, error){
+	ActionCreateOrder: func(args []string) (Data, error) {
+		_, err := parseInts(args, 0)
+		return CreateOrder{}, err
+	},
+	ActionTakeOrder:     withID(func(id int) Data { return TakeOrder{OrderID: id} }),
+	ActionArrived:       withID(func(id int) Data { return Arrived{OrderID: id} }),
+	ActionStarted:       withID(func(id int) Data { return Started{OrderID: id} }),
+	ActionFinished:      withID(func(id int) Data { return Finished{OrderID: id} }),
+	ActionCancelOrder:   withID(func(id int) Data { return CancelOrder{OrderID: id} }),
+	ActionApproveDriver: withID(func(id int) Data { return ApproveDriver{DriverID: id} }),
+	ActionRejectDriver:  withID(func(id int) Data { return RejectDriver{DriverID: id} }),
+	ActionConfirmOrder:  withID(func(id int) Data { return ConfirmOrder{OrderID: id} }),
+	ActionRepeatOrder:   withID(func(id int) Data { return RepeatOrder{OrderID: id} }),
+	ActionDeletePlace:   withID(func(id int) Data { return DeletePlace{PlaceID: id} }),
+	ActionEditProfile: func(args []string) (Data, error) {
+		if len(args) != 1 || args[0] == "" {
+			return nil, ErrMalformed
+		}
+		return EditProfile{Step: args[0]}, nil
+	},
+	ActionSavePlace: func(args []string) (Data, error) {
+		if len(args) != 2 || args[1] == "" {
+			return nil, ErrMalformed
+		}
+		ids, err := parseInts(args[:1], 1)
+		if err != nil {
+			return nil, err
+		}
+		return SavePlace{OrderID: ids[0], Step: args[1]}, nil
+	},
+	ActionOrderHistory: func(args []string) (Data, error) {
+		ints, err := parseInts(args, 1)
+		if err != nil {
+			return nil, err
+		}
+		return OrderHistory{Page: ints[0]}, nil
+	},
+	ActionRateOrder: func(args []string) (Data, error) {
+		ints, err := parseInts(args, 2)
+		if err != nil {
+			return nil, err
+		}
+		return RateOrder{OrderID: ints[0], Score: ints[1]}, nil
+	},
+	ActionEditOrder: func(args []string) (Data, error) {
+		if len(args) != 2 || args[1] == "" {
+			return nil, ErrMalformed
+		}
+		ids, err := parseInts(args[:1], 1)
+		if err != nil {
+			return nil, err
+		}
+		return EditOrder{OrderID: ids[0], Step: args[1]}, nil
+	},
+	ActionSetLanguage: func(args []string) (Data, error) {
+		if len(args) != 1 || args[0] == "" {
+			return nil, ErrMalformed
+		}
+		return SetLanguage{Lang: args[0]}, nil
+	},
+}
+
+// withID decodes the data of actions that only carry an ID.
+func withID(data func(id int) Data) func(args []string) (Data, error) {
+	return func(args []string) (Data, error) {
+		ids, err := parseInts(args, 1)
+		if err != nil {
+			return nil, err
+		}
+		return data(ids[0]), nil
+	}
+}
+
+type CreateOrder struct{}
+
+func (CreateOrder) Action() Action { return ActionCreateOrder }
+func (CreateOrder) args() []string { return nil }
+
+type TakeOrder struct{ OrderID int }
+
+func (TakeOrder) Action() Action   { return ActionTakeOrder }
+func (d TakeOrder) args() []string { return []string{formatInt(d.OrderID)} }
+
+type Arrived struct{ OrderID int }
+
+func (Arrived) Action() Action   { return ActionArrived }
+func (d Arrived) args() []string { return []string{formatInt(d.OrderID)} }
+
+type Started struct{ OrderID int }
+
+func (Started) Action() Action   { return ActionStarted }
+func (d Started) args() []string { return []string{formatInt(d.OrderID)} }
+
+type Finished struct{ OrderID int }
+
+func (Finished) Action() Action   { return ActionFinished }
+func (d Finished) args() []string { return []string{formatInt(d.OrderID)} }
+
+type CancelOrder struct{ OrderID int }
+
+func (CancelOrder) Action() Action   { return ActionCancelOrder }
+func (d CancelOrder) args() []string { return []string{formatInt(d.OrderID)} }
+
+type ApproveDriver struct{ DriverID int }
+
+func (ApproveDriver) Action() Action   { return ActionApproveDriver }
+func (d ApproveDriver) args() []string { return []string{formatInt(d.DriverID)} }
+
+type RejectDriver struct{ DriverID int }
+
+func (RejectDriver) Action() Action   { return ActionRejectDriver }
+func (d RejectDriver) args() []string { return []string{formatInt(d.DriverID)} }
+
+type SetLanguage struct{ Lang string }
+
+func (SetLanguage) Action() Action   { return ActionSetLanguage }
+func (d SetLanguage) args() []string { return []string{d.Lang} }
+
+// EditOrder asks the customer a step of the order form again.
+type EditOrder struct {
+	OrderID int
+	Step    string
+}
+
+func (EditOrder) Action() Action   { return ActionEditOrder }
+func (d EditOrder) args() []string { return []string{formatInt(d.OrderID), d.Step} }
+
+type ConfirmOrder struct{ OrderID int }
+
+func (ConfirmOrder) Action() Action   { return ActionConfirmOrder }
+func (d ConfirmOrder) args() []string { return []string{formatInt(d.OrderID)} }
+
+type RateOrder struct {
+	OrderID int
+	Score   int
+}
+
+func (RateOrder) Action() Action { return ActionRateOrder }
+func (d RateOrder) args() []string {
+	return []string{formatInt(d.OrderID), formatInt(d.Score)}
+}
+
+// OrderHistory shows a page of the customer's orders, 0 is the newest one.
+type OrderHistory struct{ Page int }
+
+func (OrderHistory) Action() Action   { return ActionOrderHistory }
+func (d OrderHistory) args() []string { return []string{formatInt(d.Page)} }
+
+// RepeatOrder starts a new order with the addresses of a past one.
+type RepeatOrder struct{ OrderID int }
+
+func (RepeatOrder) Action() Action   { return ActionRepeatOrder }
+func (d RepeatOrder) args() []string { return []string{formatInt(d.OrderID)} }
+
+// EditProfile asks the user a step of the profile form.
+type EditProfile struct{ Step string }
+
+func (EditProfile) Action() Action   { return ActionEditProfile }
+func (d EditProfile) args() []string { return []string{d.Step} }
+
+// SavePlace asks the customer to name an address of a finished order. Step
+// is the order form step of the address.
+type SavePlace struct {
+	OrderID int
+	Step    string
+}
+
+func (SavePlace) Action() Action   { return ActionSavePlace }
+func (d SavePlace) args() []string { return []string{formatInt(d.OrderID), d.Step} }
+
+type DeletePlace struct{ PlaceID int }
+
+func (DeletePlace) Action() Action   { return ActionDeletePlace }
+func (d DeletePlace) args() []string { return []string{formatInt(d.PlaceID)} }
@@ -0,0 +1,120 @@
+package callback
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Callback data looks like "<version>:<action>[:<arg>...]:<mac>". Telegram
+// allows at most 64 bytes of it.
+const (
+	version   = "1"
+	separator = ":"
+	maxLength = 64
+	// macBytes of HMAC-SHA256 are kept, 8 characters once encoded.
+	macBytes = 6
+)
+
+var (
+	ErrMalformed     = errors.New("callback: malformed data")
+	ErrSignature     = errors.New("callback: bad signature")
+	ErrVersion       = errors.New("callback: unsupported version")
+	ErrUnknownAction = errors.New("callback: unknown action")
+)
+
+type Action string
+
+// Data is a decoded callback payload. Every action has its own type.
+type Data interface {
+	Action() Action
+	args() []string
+}
+
+// Codec encodes callback data and authenticates it, so that a client
+// cannot press a button it has never been shown.
+type Codec struct {
+	key []byte
+}
+
+func New(secret string) *Codec {
+	return &Codec{key: []byte(secret)}
+}
+
+// Encode panics if the data does not fit into 64 bytes: actions and their
+// arguments are chosen by the code, not by users.
+func (c *Codec) Encode(d Data) string {
+	parts := append([]string{version, string(d.Action())}, d.args()...)
+	payload := strings.Join(parts, separator)
+	encoded := payload + separator + c.sign(payload)
+	if len(encoded) > maxLength {
+		panic(fmt.Sprintf("callback: %q is %d bytes long", encoded, len(encoded)))
+	}
+	return encoded
+}
+
+func (c *Codec) Decode(data string) (Data, error) {
+	i := strings.LastIndex(data, separator)
+	if i < 0 {
+		return nil, ErrMalformed
+	}
+	payload, mac := data[:i], data[i+1:]
+	if !hmac.Equal([]byte(mac), []byte(c.sign(payload))) {
+		return nil, ErrSignature
+	}
+	parts := strings.Split(payload, separator)
+	if len(parts) < 2 {
+		return nil, ErrMalformed
+	}
+	if parts[0] != version {
+		return nil, ErrVersion
+	}
+	decode, ok := decoders[Action(parts[1])]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownAction, parts[1])
+	}
+	d, err := decode(parts[2:])
+	if err != nil {
+		return nil, fmt.Errorf("callback %s: %w", parts[1], err)
+	}
+	return d, nil
+}
+
+// ActionOf returns the action of the data without checking the signature.
+// It is meant for logging only.
+func ActionOf(data string) Action {
+	parts := strings.SplitN(data, separator, 3)
+	if len(parts) < 2 {
+		return ""
+	}
+	return Action(parts[1])
+}
+
+func (c *Codec) sign(payload string) string {
+	mac := hmac.New(sha256.New, c.key)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:macBytes])
+}
+
+func formatInt(i int) string {
+	return strconv.FormatInt(int64(i), 36)
+}
+
+func parseInts(args []string, n int) ([]int, error) {
+	if len(args) != n {
+		return nil, fmt.Errorf("%w: want %d args, got %d", ErrMalformed, n, len(args))
+	}
+	ints := make([]int, n)
+	for i, arg := range args {
+		v, err := strconv.ParseInt(arg, 36, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+		}
+		ints[i] = int(v)
+	}
+	return ints, nil
+}
@@ -0,0 +1,88 @@
+package callback
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCodecRoundTrip(t *testing.T) {
+	c := New("secret")
+	tests := []Data{
+		CreateOrder{},
+		TakeOrder{OrderID: 123456},
+		Arrived{OrderID: 1},
+		Started{OrderID: 2},
+		Finished{OrderID: 3},
+		CancelOrder{OrderID: 4},
+		ApproveDriver{DriverID: 5},
+		RejectDriver{DriverID: 6},
+		SetLanguage{Lang: "en"},
+		EditOrder{OrderID: 7, Step: "destination"},
+		ConfirmOrder{OrderID: 8},
+		RateOrder{OrderID: 9, Score: 5},
+		OrderHistory{Page: 2},
+		RepeatOrder{OrderID: 10},
+		EditProfile{Step: "phone"},
+		SavePlace{OrderID: 11, Step: "source"},
+		DeletePlace{PlaceID: 12},
+	}
+	for _, want := range tests {
+		t.Run(string(want.Action()), func(t *testing.T) {
+			encoded := c.Encode(want)
+			if len(encoded) > maxLength {
+				t.Errorf("Encode(%v) is %d bytes long", want, len(encoded))
+			}
+			if action := ActionOf(encoded); action != want.Action() {
+				t.Errorf("ActionOf(%q) = %q, want %q", encoded, action, want.Action())
+			}
+			got, err := c.Decode(encoded)
+			if err != nil {
+				t.Fatalf("Decode(%q): %v", encoded, err)
+			}
+			if got != want {
+				t.Errorf("Decode(%q) = %#v, want %#v", encoded, got, want)
+			}
+		})
+	}
+}
+
+func TestCodecDecodeRejects(t *testing.T) {
+	c := New("secret")
+	valid := c.Encode(TakeOrder{OrderID: 42})
+	payload := valid[:strings.LastIndex(valid, separator)]
+	signed := func(payload string) string { return payload + separator + c.sign(payload) }
+
+	tests := []struct {
+		name string
+		data string
+		want error
+	}{
+		{name: "other order", data: strings.Replace(valid, ":16:", ":17:", 1), want: ErrSignature},
+		{name: "other action", data: strings.Replace(valid, ":order:", ":arrived:", 1), want: ErrSignature},
+		{name: "other secret", data: New("other").Encode(TakeOrder{OrderID: 42}), want: ErrSignature},
+		{name: "no signature", data: payload, want: ErrSignature},
+		{name: "legacy data", data: "order:42", want: ErrSignature},
+		{name: "no separator", data: "order", want: ErrMalformed},
+		{name: "old version", data: signed("0:order:16"), want: ErrVersion},
+		{name: "unknown action", data: signed("1:fly:16"), want: ErrUnknownAction},
+		{name: "missing argument", data: signed("1:order"), want: ErrMalformed},
+		{name: "bad argument", data: signed("1:order:!"), want: ErrMalformed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := c.Decode(tt.data); !errors.Is(err, tt.want) {
+				t.Errorf("Decode(%q) error = %v, want %v", tt.data, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodeTooLong(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Encode did not panic on data longer than 64 bytes")
+		}
+	}()
+	New("secret").Encode(EditProfile{Step: strings.Repeat("x", maxLength)})
+}
@@ -1,4 +1,7 @@
 #!/bin/bash
+set -e
 
 cp /home/ec2-user/bot /home/ec2-user/bot-running
+# The bot refuses to start while migrations are pending.
+/home/ec2-user/bot-running migrate up
 /home/ec2-user/bot-running
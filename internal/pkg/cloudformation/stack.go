@@ -45,6 +45,9 @@ var restartBot string
 //go:embed bot/start.sh
 var startBot string
 
+//go:embed bot/bot.env
+var botEnv string
+
 func NewStack(scope constructs.Construct, id string, props *awscdk.StackProps) awscdk.Stack {
 	stack := awscdk.NewStack(scope, &id, props)
 
@@ -65,6 +68,26 @@ func NewStack(scope constructs.Construct, id string, props *awscdk.StackProps) a
 		Type:        ptr.Of("String"),
 	})
 
+	driverBotToken := newSecretParameter(stack, "DriverBotToken", "Telegram token of the driver bot")
+	customerBotToken := newSecretParameter(stack, "CustomerBotToken", "Telegram token of the customer bot")
+	callbackSecret := newSecretParameter(stack, "CallbackSecret", "Key signing the data of inline buttons")
+	yooKassaSecretKey := newSecretParameter(stack, "YooKassaSecretKey", "YooKassa secret key")
+
+	driversChatID := awscdk.NewCfnParameter(stack, ptr.Of("DriversChatID"), &awscdk.CfnParameterProps{
+		Description: ptr.Of("Telegram chat where orders are published to drivers"),
+		Type:        ptr.Of("String"),
+	})
+
+	adminChatID := awscdk.NewCfnParameter(stack, ptr.Of("AdminChatID"), &awscdk.CfnParameterProps{
+		Description: ptr.Of("Telegram chat of the administrators"),
+		Type:        ptr.Of("String"),
+	})
+
+	yooKassaShopID := awscdk.NewCfnParameter(stack, ptr.Of("YooKassaShopID"), &awscdk.CfnParameterProps{
+		Description: ptr.Of("YooKassa shop ID"),
+		Type:        ptr.Of("String"),
+	})
+
 	defaultVpc := awsec2.Vpc_FromLookup(stack, ptr.Of("VPC"), &awsec2.VpcLookupOptions{
 		IsDefault: ptr.Of(true),
 		Region:    stack.Region(),
@@ -153,6 +176,26 @@ func NewStack(scope constructs.Construct, id string, props *awscdk.StackProps) a
 		nil,
 	)
 
+	dbInstance := awsrds.NewCfnDBInstance(stack, ptr.Of("DBInstance"), &awsrds.CfnDBInstanceProps{
+		AllocatedStorage:     ptr.Of("20"),
+		PubliclyAccessible:   ptr.Of(true),
+		MasterUsername:       dbUsername.ValueAsString(),
+		MasterUserPassword:   dbPassword.ValueAsString(),
+		VpcSecurityGroups:    &[]*string{dbSg.SecurityGroupId()},
+		EngineVersion:        ptr.Of("14.6"),
+		Engine:               ptr.Of("postgres"),
+		DbInstanceClass:      ptr.Of("db.t3.micro"),
+		DbInstanceIdentifier: ptr.Of("db"),
+	})
+
+	// The password goes into the URL as is, so it must not contain URL special characters.
+	databaseURL := awscdk.Fn_Sub(ptr.Of("postgresql://${User}:${Password}@${Host}:${Port}/postgres"), &map[string]*string{
+		"User":     dbUsername.ValueAsString(),
+		"Password": dbPassword.ValueAsString(),
+		"Host":     dbInstance.AttrEndpointAddress(),
+		"Port":     dbInstance.AttrEndpointPort(),
+	})
+
 	autoScalingGroup := awsautoscaling.NewAutoScalingGroup(stack, ptr.Of("EC2Instance"), &awsautoscaling.AutoScalingGroupProps{
 		InstanceType: awsec2.NewInstanceType(ptr.Of("t2.micro")),
 		MachineImage: awsec2.MachineImage_LatestAmazonLinux(&awsec2.AmazonLinuxImageProps{
@@ -282,6 +325,24 @@ func NewStack(scope constructs.Construct, id string, props *awscdk.StackProps) a
 								Mode:  ptr.Of("000655"),
 							},
 						),
+						awsec2.InitFile_FromString(
+							ptr.Of("/etc/bot.env"),
+							awscdk.Fn_Sub(ptr.Of(botEnv), &map[string]*string{
+								"DriverBotToken":    driverBotToken.ValueAsString(),
+								"CustomerBotToken":  customerBotToken.ValueAsString(),
+								"CallbackSecret":    callbackSecret.ValueAsString(),
+								"DriversChatID":     driversChatID.ValueAsString(),
+								"AdminChatID":       adminChatID.ValueAsString(),
+								"DatabaseURL":       databaseURL,
+								"YooKassaShopID":    yooKassaShopID.ValueAsString(),
+								"YooKassaSecretKey": yooKassaSecretKey.ValueAsString(),
+							}),
+							&awsec2.InitFileOptions{
+								Group: ptr.Of("root"),
+								Owner: ptr.Of("root"),
+								Mode:  ptr.Of("000400"),
+							},
+						),
 						awsec2.InitFile_FromString(
 							ptr.Of("/lib/systemd/system/bot.service"),
 							ptr.Of(botService),
@@ -310,22 +371,25 @@ func NewStack(scope constructs.Construct, id string, props *awscdk.StackProps) a
 
 	autoScalingGroup.AddSecurityGroup(ec2Sg)
 
-	awsrds.NewCfnDBInstance(stack, ptr.Of("DBInstance"), &awsrds.CfnDBInstanceProps{
-		AllocatedStorage:     ptr.Of("20"),
-		PubliclyAccessible:   ptr.Of(true),
-		MasterUsername:       dbUsername.ValueAsString(),
-		MasterUserPassword:   dbPassword.ValueAsString(),
-		VpcSecurityGroups:    &[]*string{dbSg.SecurityGroupId()},
-		EngineVersion:        ptr.Of("14.6"),
-		Engine:               ptr.Of("postgres"),
-		DbInstanceClass:      ptr.Of("db.t3.micro"),
-		DbInstanceIdentifier: ptr.Of("db"),
-	})
-
 	awscdklambdagoalpha.NewGoFunction(stack, ptr.Of("NotifyAboutDeployLambda"), &awscdklambdagoalpha.GoFunctionProps{
 		FunctionName: ptr.Of("TriggerForFormInSite"),
 		Entry:        ptr.Of("cmd/lambda"),
+		Environment: &map[string]*string{
+			"TELEGRAM_DRIVER_BOT_TOKEN": driverBotToken.ValueAsString(),
+			"TELEGRAM_CALLBACK_SECRET":  callbackSecret.ValueAsString(),
+			"TELEGRAM_DRIVERS_CHAT_ID":  driversChatID.ValueAsString(),
+			"TELEGRAM_ADMIN_CHAT_ID":    adminChatID.ValueAsString(),
+			"DATABASE_URL":              databaseURL,
+		},
 	})
 
 	return stack
 }
+
+func newSecretParameter(stack awscdk.Stack, id, description string) awscdk.CfnParameter {
+	return awscdk.NewCfnParameter(stack, ptr.Of(id), &awscdk.CfnParameterProps{
+		NoEcho:      ptr.Of(true),
+		Description: ptr.Of(description),
+		Type:        ptr.Of("String"),
+	})
+}
@@ -0,0 +1,292 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultYooKassaPaymentsURL = "https://api.yookassa.ru/v3/payments"
+const defaultYooKassaReturnURL = "https://t.me/PerfectDriverBot"
+const defaultTimezone = "Europe/Moscow"
+const defaultWebhookAddress = ":8080"
+const defaultUpdatesWorkers = 8
+const defaultUpdatesQueueSize = 64
+
+const (
+	ModePolling = "polling"
+	ModeWebhook = "webhook"
+)
+
+var secretTokenRe = regexp.MustCompile(`^[A-Za-z0-9_-]{1,256}$`)
+
+type Telegram struct {
+	DriverBotToken   string `json:"driver_bot_token"`
+	CustomerBotToken string `json:"customer_bot_token"`
+	DriversChatID    int64  `json:"drivers_chat_id"`
+	AdminChatID      int64  `json:"admin_chat_id"`
+	// CallbackSecret signs the data of inline buttons.
+	CallbackSecret string `json:"callback_secret"`
+	// Mode is how updates are received: "polling" (default) or "webhook".
+	Mode    string  `json:"mode"`
+	Webhook Webhook `json:"webhook"`
+}
+
+type Webhook struct {
+	// Address is where the HTTP server listens, e.g. ":8080".
+	Address string `json:"address"`
+	// URL is the public base URL Telegram sends updates to. Each bot gets
+	// its own path under it.
+	URL         string `json:"url"`
+	SecretToken string `json:"secret_token"`
+}
+
+type Updates struct {
+	// Workers is the number of updates of every bot processed in parallel.
+	Workers   int `json:"workers"`
+	QueueSize int `json:"queue_size"`
+	// MetricsAddress serves expvar metrics (/debug/vars) in both modes. It
+	// must not be reachable from the internet, metrics are off when empty.
+	MetricsAddress string `json:"metrics_address"`
+}
+
+type Database struct {
+	URL string `json:"url"`
+}
+
+type YooKassa struct {
+	ShopID      int64  `json:"shop_id"`
+	SecretKey   string `json:"secret_key"`
+	PaymentsURL string `json:"payments_url"`
+	ReturnURL   string `json:"return_url"`
+}
+
+type Cancellation struct {
+	// LateFee is charged in rubles when the customer cancels after a driver
+	// took the order. Zero disables the fee.
+	LateFee int64 `json:"late_fee"`
+}
+
+type Commission struct {
+	// Percent of the fare drivers pay to the service for every finished
+	// order.
+	Percent int64 `json:"percent"`
+}
+
+type Config struct {
+	Telegram     Telegram     `json:"telegram"`
+	Updates      Updates      `json:"updates"`
+	Database     Database     `json:"database"`
+	YooKassa     YooKassa     `json:"yookassa"`
+	Cancellation Cancellation `json:"cancellation"`
+	Commission   Commission   `json:"commission"`
+	// Timezone is the IANA name of the city's timezone. Pickup times typed by
+	// customers are interpreted in it.
+	Timezone string `json:"timezone"`
+}
+
+func (c *Config) Location() (*time.Location, error) {
+	loc, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("time.LoadLocation(%s): %w", c.Timezone, err)
+	}
+	return loc, nil
+}
+
+// Load reads the config from the JSON file at CONFIG_PATH (if set),
+// overrides it with environment variables and validates the result.
+func Load() (*Config, error) {
+	c, err := load()
+	if err != nil {
+		return nil, err
+	}
+	if err = c.Validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// LoadDatabase is like Load but only requires the database settings. It is
+// used by tooling such as the migrate subcommand.
+func LoadDatabase() (Database, error) {
+	c, err := load()
+	if err != nil {
+		return Database{}, err
+	}
+	if c.Database.URL == "" {
+		return Database{}, errors.New("config: missing required fields: database.url")
+	}
+	return c.Database, nil
+}
+
+// LoadLambda is like Load but only requires what the Lambda uses: the driver
+// bot, its chats and the database.
+func LoadLambda() (*Config, error) {
+	c, err := load()
+	if err != nil {
+		return nil, err
+	}
+	err = checkRequired([]requiredField{
+		{"telegram.driver_bot_token", c.Telegram.DriverBotToken == ""},
+		{"telegram.drivers_chat_id", c.Telegram.DriversChatID == 0},
+		{"telegram.admin_chat_id", c.Telegram.AdminChatID == 0},
+		{"telegram.callback_secret", c.Telegram.CallbackSecret == ""},
+		{"database.url", c.Database.URL == ""},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, err = c.Location(); err != nil {
+		return nil, fmt.Errorf("config: timezone: %w", err)
+	}
+	return c, nil
+}
+
+func load() (*Config, error) {
+	c := &Config{}
+	if path := os.Getenv("CONFIG_PATH"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("os.ReadFile(%s): %w", path, err)
+		}
+		if err = json.Unmarshal(data, c); err != nil {
+			return nil, fmt.Errorf("json.Unmarshal(%s): %w", path, err)
+		}
+	}
+
+	setString(&c.Telegram.DriverBotToken, "TELEGRAM_DRIVER_BOT_TOKEN")
+	setString(&c.Telegram.CustomerBotToken, "TELEGRAM_CUSTOMER_BOT_TOKEN")
+	setString(&c.Telegram.CallbackSecret, "TELEGRAM_CALLBACK_SECRET")
+	setString(&c.Telegram.Mode, "TELEGRAM_MODE")
+	setString(&c.Telegram.Webhook.Address, "TELEGRAM_WEBHOOK_ADDRESS")
+	setString(&c.Telegram.Webhook.URL, "TELEGRAM_WEBHOOK_URL")
+	setString(&c.Telegram.Webhook.SecretToken, "TELEGRAM_WEBHOOK_SECRET_TOKEN")
+	setString(&c.Updates.MetricsAddress, "UPDATES_METRICS_ADDRESS")
+	setString(&c.Database.URL, "DATABASE_URL")
+	setString(&c.YooKassa.SecretKey, "YOOKASSA_SECRET_KEY")
+	setString(&c.YooKassa.PaymentsURL, "YOOKASSA_PAYMENTS_URL")
+	setString(&c.YooKassa.ReturnURL, "YOOKASSA_RETURN_URL")
+	setString(&c.Timezone, "TIMEZONE")
+	err := errors.Join(
+		setInt(&c.Telegram.DriversChatID, "TELEGRAM_DRIVERS_CHAT_ID"),
+		setInt(&c.Telegram.AdminChatID, "TELEGRAM_ADMIN_CHAT_ID"),
+		setInt(&c.YooKassa.ShopID, "YOOKASSA_SHOP_ID"),
+		setInt(&c.Cancellation.LateFee, "CANCELLATION_LATE_FEE"),
+		setInt(&c.Commission.Percent, "COMMISSION_PERCENT"),
+		setInt(&c.Updates.Workers, "UPDATES_WORKERS"),
+		setInt(&c.Updates.QueueSize, "UPDATES_QUEUE_SIZE"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.YooKassa.PaymentsURL == "" {
+		c.YooKassa.PaymentsURL = defaultYooKassaPaymentsURL
+	}
+	if c.YooKassa.ReturnURL == "" {
+		c.YooKassa.ReturnURL = defaultYooKassaReturnURL
+	}
+	if c.Timezone == "" {
+		c.Timezone = defaultTimezone
+	}
+	if c.Updates.Workers <= 0 {
+		c.Updates.Workers = defaultUpdatesWorkers
+	}
+	if c.Updates.QueueSize <= 0 {
+		c.Updates.QueueSize = defaultUpdatesQueueSize
+	}
+	if c.Telegram.Mode == "" {
+		c.Telegram.Mode = ModePolling
+	}
+	if c.Telegram.Webhook.Address == "" {
+		c.Telegram.Webhook.Address = defaultWebhookAddress
+	}
+	return c, nil
+}
+
+type requiredField struct {
+	name  string
+	empty bool
+}
+
+func checkRequired(fields []requiredField) error {
+	var missing []string
+	for _, field := range fields {
+		if field.empty {
+			missing = append(missing, field.name)
+		}
+	}
+	if len(missing) != 0 {
+		return fmt.Errorf("config: missing required fields: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+func (c *Config) Validate() error {
+	err := checkRequired([]requiredField{
+		{"telegram.driver_bot_token", c.Telegram.DriverBotToken == ""},
+		{"telegram.customer_bot_token", c.Telegram.CustomerBotToken == ""},
+		{"telegram.drivers_chat_id", c.Telegram.DriversChatID == 0},
+		{"telegram.admin_chat_id", c.Telegram.AdminChatID == 0},
+		{"telegram.callback_secret", c.Telegram.CallbackSecret == ""},
+		{"database.url", c.Database.URL == ""},
+		{"yookassa.shop_id", c.YooKassa.ShopID == 0},
+		{"yookassa.secret_key", c.YooKassa.SecretKey == ""},
+	})
+	if err != nil {
+		return err
+	}
+	if _, err = c.Location(); err != nil {
+		return fmt.Errorf("config: timezone: %w", err)
+	}
+	if c.Commission.Percent < 0 || c.Commission.Percent > 100 {
+		return fmt.Errorf("config: commission.percent: must be between 0 and 100, got %d", c.Commission.Percent)
+	}
+	return c.Telegram.validateMode()
+}
+
+func (t *Telegram) validateMode() error {
+	switch t.Mode {
+	case ModePolling:
+		return nil
+	case ModeWebhook:
+	default:
+		return fmt.Errorf("config: telegram.mode: unknown mode %q", t.Mode)
+	}
+	if t.Webhook.URL == "" {
+		return errors.New("config: missing required fields: telegram.webhook.url")
+	}
+	u, err := url.Parse(t.Webhook.URL)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("config: telegram.webhook.url: must be an https URL, got %q", t.Webhook.URL)
+	}
+	if !secretTokenRe.MatchString(t.Webhook.SecretToken) {
+		return errors.New("config: telegram.webhook.secret_token: must be 1-256 characters A-Z, a-z, 0-9, _ or -")
+	}
+	return nil
+}
+
+func setString(dst *string, env string) {
+	if v, ok := os.LookupEnv(env); ok {
+		*dst = v
+	}
+}
+
+func setInt[T int | int64](dst *T, env string) error {
+	v, ok := os.LookupEnv(env)
+	if !ok {
+		return nil
+	}
+	i, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return fmt.Errorf("config: %s: %w", env, err)
+	}
+	*dst = T(i)
+	return nil
+}
@@ -0,0 +1,329 @@
+// Package dialog runs forms that ask the user one question per message,
+// e.g. the order form of the customer bot.
+package dialog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mymmrac/telego"
+
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/i18n"
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/models"
+)
+
+const shortcutsPerRow = 2
+
+type InputType int
+
+const (
+	// InputText is a typed text.
+	InputText InputType = iota
+	// InputLocation is a typed address, a shared location or a venue.
+	InputLocation
+	// InputContact is a shared contact or a typed phone number.
+	InputContact
+	// InputChoice is one of the step's choices.
+	InputChoice
+)
+
+// Input is the answer of the user to a step.
+type Input struct {
+	Message *telego.Message
+	// Text is the typed text, the address of a venue or the phone of a
+	// contact. It is empty for a bare location.
+	Text  string
+	Point *models.Point
+	// Choice is the value of the picked choice.
+	Choice string
+}
+
+type Choice struct {
+	Value string
+	Label i18n.Key
+}
+
+// Shortcut is a ready answer offered as a keyboard button, e.g. a saved
+// address. Pressing it answers the step with Input.
+type Shortcut struct {
+	Label string
+	Input Input
+}
+
+// InvalidError rejects an answer. The text is sent to the user and the step
+// is asked again.
+type InvalidError struct {
+	Text string
+}
+
+func (e *InvalidError) Error() string {
+	return "invalid input: " + e.Text
+}
+
+func Invalid(text string) error {
+	return &InvalidError{Text: text}
+}
+
+type Step[S any] struct {
+	// Name identifies the step in the stored position of the flow.
+	Name    string
+	Prompt  i18n.Key
+	Input   InputType
+	Choices []Choice
+	// Shortcuts is optional. It returns the ready answers of the state.
+	Shortcuts func(ctx context.Context, state S) []Shortcut
+	// Optional steps can be skipped.
+	Optional bool
+	// Filled reports whether the state already has an answer to the step.
+	Filled func(state S) bool
+	// Validate is optional. It returns an error made by Invalid to ask again.
+	Validate func(ctx context.Context, state S, in Input) error
+	// Save stores the answer in the state.
+	Save func(ctx context.Context, state S, in Input) error
+}
+
+// Flow is a list of steps asked in order. The position of a state is the
+// step it is at; a state without a stored position is at its first step
+// that is not filled. After an answer the flow moves to the next step that
+// is not filled, so states that already have some answers, e.g. repeated
+// orders, are only asked for what is missing.
+type Flow[S any] struct {
+	Steps []Step[S]
+	// Position returns the name of the step the state is at, or "".
+	Position    func(state S) string
+	SetPosition func(ctx context.Context, state S, step string) error
+	// Finish is called once every step is answered.
+	Finish func(ctx context.Context, state S) error
+}
+
+// Conversation is the chat the flow is asked in.
+type Conversation struct {
+	Bot     *telego.Bot
+	ChatID  int64
+	Printer i18n.Printer
+}
+
+// Start asks the first step the state is missing.
+func (f *Flow[S]) Start(ctx context.Context, c Conversation, state S) error {
+	return f.moveTo(ctx, c, state, f.next(state, 0))
+}
+
+// Goto asks the named step, e.g. to change one answer of a filled state.
+func (f *Flow[S]) Goto(ctx context.Context, c Conversation, state S, step string) error {
+	i := f.index(step)
+	if i < 0 {
+		return fmt.Errorf("dialog: unknown step %q", step)
+	}
+	return f.moveTo(ctx, c, state, i)
+}
+
+// Handle takes the message as the answer to the current step.
+func (f *Flow[S]) Handle(ctx context.Context, c Conversation, state S, message *telego.Message) error {
+	current := f.current(state)
+	if current == len(f.Steps) {
+		return f.Finish(ctx, state)
+	}
+	step := f.Steps[current]
+
+	switch message.Text {
+	case c.Printer.T(i18n.ButtonBack):
+		if current == 0 {
+			return f.ask(ctx, c, state, current, c.Printer.T(step.Prompt))
+		}
+		return f.moveTo(ctx, c, state, current-1)
+	case c.Printer.T(i18n.ButtonSkip):
+		if !step.Optional {
+			return f.ask(ctx, c, state, current, c.Printer.T(step.Prompt))
+		}
+		return f.moveTo(ctx, c, state, f.next(state, current+1))
+	}
+
+	in, ok := shortcut(ctx, step, state, message)
+	if !ok {
+		in, ok = read(step, c.Printer, message)
+	}
+	if !ok {
+		return f.ask(ctx, c, state, current, c.Printer.T(hint(step)))
+	}
+	err := f.save(ctx, step, state, in)
+	var invalidErr *InvalidError
+	if errors.As(err, &invalidErr) {
+		return f.ask(ctx, c, state, current, invalidErr.Text)
+	}
+	if err != nil {
+		return fmt.Errorf("step %s: %w", step.Name, err)
+	}
+	return f.moveTo(ctx, c, state, f.next(state, current+1))
+}
+
+func (f *Flow[S]) save(ctx context.Context, step Step[S], state S, in Input) error {
+	if step.Validate != nil {
+		if err := step.Validate(ctx, state, in); err != nil {
+			return err
+		}
+	}
+	return step.Save(ctx, state, in)
+}
+
+func (f *Flow[S]) current(state S) int {
+	if i := f.index(f.Position(state)); i >= 0 {
+		return i
+	}
+	return f.next(state, 0)
+}
+
+func (f *Flow[S]) index(step string) int {
+	for i := range f.Steps {
+		if f.Steps[i].Name == step {
+			return i
+		}
+	}
+	return -1
+}
+
+// next returns the first step from i on that is not filled.
+func (f *Flow[S]) next(state S, i int) int {
+	for ; i < len(f.Steps); i++ {
+		if !f.Steps[i].Filled(state) {
+			return i
+		}
+	}
+	return len(f.Steps)
+}
+
+func (f *Flow[S]) moveTo(ctx context.Context, c Conversation, state S, i int) error {
+	if i == len(f.Steps) {
+		return f.Finish(ctx, state)
+	}
+	if err := f.SetPosition(ctx, state, f.Steps[i].Name); err != nil {
+		return fmt.Errorf("SetPosition: %w", err)
+	}
+	return f.ask(ctx, c, state, i, c.Printer.T(f.Steps[i].Prompt))
+}
+
+func (f *Flow[S]) ask(ctx context.Context, c Conversation, state S, i int, text string) error {
+	_, err := c.Bot.SendMessage(&telego.SendMessageParams{
+		ChatID:      telego.ChatID{ID: c.ChatID},
+		Text:        text,
+		ReplyMarkup: f.keyboard(ctx, c.Printer, state, i),
+	})
+	if err != nil {
+		return fmt.Errorf("SendMessage: %w", err)
+	}
+	return nil
+}
+
+func (f *Flow[S]) keyboard(ctx context.Context, p i18n.Printer, state S, i int) telego.ReplyMarkup {
+	step := f.Steps[i]
+	var rows [][]telego.KeyboardButton
+	if step.Shortcuts != nil {
+		var row []telego.KeyboardButton
+		for _, s := range step.Shortcuts(ctx, state) {
+			row = append(row, telego.KeyboardButton{Text: s.Label})
+			if len(row) == shortcutsPerRow {
+				rows = append(rows, row)
+				row = nil
+			}
+		}
+		if len(row) != 0 {
+			rows = append(rows, row)
+		}
+	}
+	switch step.Input {
+	case InputLocation:
+		rows = append(rows, []telego.KeyboardButton{{Text: p.T(i18n.ButtonSendLocation), RequestLocation: true}})
+	case InputContact:
+		rows = append(rows, []telego.KeyboardButton{{Text: p.T(i18n.ButtonShareContact), RequestContact: true}})
+	case InputChoice:
+		for _, choice := range step.Choices {
+			rows = append(rows, []telego.KeyboardButton{{Text: p.T(choice.Label)}})
+		}
+	}
+	var navigation []telego.KeyboardButton
+	if i > 0 {
+		navigation = append(navigation, telego.KeyboardButton{Text: p.T(i18n.ButtonBack)})
+	}
+	if step.Optional {
+		navigation = append(navigation, telego.KeyboardButton{Text: p.T(i18n.ButtonSkip)})
+	}
+	if len(navigation) != 0 {
+		rows = append(rows, navigation)
+	}
+	if len(rows) == 0 {
+		return &telego.ReplyKeyboardRemove{RemoveKeyboard: true}
+	}
+	return &telego.ReplyKeyboardMarkup{Keyboard: rows, ResizeKeyboard: true, OneTimeKeyboard: true}
+}
+
+// shortcut returns the input of the shortcut the message is the label of.
+func shortcut[S any](ctx context.Context, step Step[S], state S, message *telego.Message) (Input, bool) {
+	text := strings.TrimSpace(message.Text)
+	if step.Shortcuts == nil || text == "" {
+		return Input{}, false
+	}
+	for _, s := range step.Shortcuts(ctx, state) {
+		if s.Label == text {
+			in := s.Input
+			in.Message = message
+			return in, true
+		}
+	}
+	return Input{}, false
+}
+
+// read converts the message to the input of the step. It returns false if
+// the message is not an answer of the step's type.
+func read[S any](step Step[S], p i18n.Printer, message *telego.Message) (Input, bool) {
+	in := Input{Message: message, Text: strings.TrimSpace(message.Text)}
+	switch step.Input {
+	case InputLocation:
+		switch {
+		case message.Venue != nil:
+			in.Point = &models.Point{
+				Latitude:  message.Venue.Location.Latitude,
+				Longitude: message.Venue.Location.Longitude,
+			}
+			in.Text = message.Venue.Title
+			if message.Venue.Address != "" {
+				in.Text = fmt.Sprintf("%s, %s", message.Venue.Title, message.Venue.Address)
+			}
+			return in, true
+		case message.Location != nil:
+			in.Point = &models.Point{
+				Latitude:  message.Location.Latitude,
+				Longitude: message.Location.Longitude,
+			}
+			return in, true
+		}
+	case InputContact:
+		if message.Contact != nil {
+			in.Text = message.Contact.PhoneNumber
+			return in, true
+		}
+	case InputChoice:
+		for _, choice := range step.Choices {
+			if in.Text == p.T(choice.Label) {
+				in.Choice = choice.Value
+				return in, true
+			}
+		}
+		return in, false
+	}
+	return in, in.Text != ""
+}
+
+// hint is the text sent when the message is not an answer of the step's
+// type.
+func hint[S any](step Step[S]) i18n.Key {
+	switch step.Input {
+	case InputLocation:
+		return i18n.AskAddress
+	case InputContact:
+		return step.Prompt
+	case InputChoice:
+		return i18n.ChooseOption
+	}
+	return i18n.AskText
+}
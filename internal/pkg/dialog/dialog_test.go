@@ -0,0 +1,251 @@
+package dialog_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"sync"
+	"testing"
+
+	"github.com/mymmrac/telego"
+
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/dialog"
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/i18n"
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/models"
+)
+
+const (
+	testToken = "123456789:AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA"
+	chatID    = int64(1001)
+)
+
+// telegramServer answers the Bot API calls and records the texts of the
+// sent messages.
+type telegramServer struct {
+	mu    sync.Mutex
+	texts []string
+}
+
+func (s *telegramServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	result := any(true)
+	if path.Base(r.URL.Path) == "sendMessage" {
+		var m struct {
+			Text string `json:"text"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&m); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.mu.Lock()
+		s.texts = append(s.texts, m.Text)
+		s.mu.Unlock()
+		result = telego.Message{MessageID: 1, Chat: telego.Chat{ID: chatID}}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "result": result})
+}
+
+func (s *telegramServer) sent() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.texts...)
+}
+
+func newConversation(t *testing.T) (dialog.Conversation, *telegramServer) {
+	t.Helper()
+	api := &telegramServer{}
+	srv := httptest.NewServer(api)
+	t.Cleanup(srv.Close)
+
+	bot, err := telego.NewBot(testToken, telego.WithAPIServer(srv.URL), telego.WithDiscardLogger())
+	if err != nil {
+		t.Fatalf("telego.NewBot: %v", err)
+	}
+	return dialog.Conversation{Bot: bot, ChatID: chatID, Printer: i18n.New(i18n.Russian)}, api
+}
+
+// order is the state of the test flow, a short version of the order form.
+type order struct {
+	position    string
+	source      string
+	sourcePoint models.Point
+	time        string
+	destination string
+	finished    bool
+}
+
+var home = dialog.Shortcut{
+	Label: "Дом",
+	Input: dialog.Input{Text: "Тверская, 1", Point: &models.Point{Latitude: 55.76, Longitude: 37.61}},
+}
+
+func newFlow() *dialog.Flow[*order] {
+	return &dialog.Flow[*order]{
+		Steps: []dialog.Step[*order]{
+			{
+				Name:   "source",
+				Prompt: i18n.AskSource,
+				Input:  dialog.InputLocation,
+				Shortcuts: func(context.Context, *order) []dialog.Shortcut {
+					return []dialog.Shortcut{home}
+				},
+				Filled: func(o *order) bool { return o.source != "" },
+				Save: func(_ context.Context, o *order, in dialog.Input) error {
+					o.source = in.Text
+					o.sourcePoint = models.Point{}
+					if in.Point != nil {
+						o.sourcePoint = *in.Point
+					}
+					return nil
+				},
+			},
+			{
+				Name:     "time",
+				Prompt:   i18n.AskTime,
+				Input:    dialog.InputText,
+				Optional: true,
+				Filled:   func(o *order) bool { return o.time != "" },
+				Save: func(_ context.Context, o *order, in dialog.Input) error {
+					o.time = in.Text
+					return nil
+				},
+			},
+			{
+				Name:   "destination",
+				Prompt: i18n.AskDestination,
+				Input:  dialog.InputLocation,
+				Filled: func(o *order) bool { return o.destination != "" },
+				Save: func(_ context.Context, o *order, in dialog.Input) error {
+					o.destination = in.Text
+					return nil
+				},
+			},
+		},
+		Position: func(o *order) string { return o.position },
+		SetPosition: func(_ context.Context, o *order, step string) error {
+			o.position = step
+			return nil
+		},
+		Finish: func(_ context.Context, o *order) error {
+			o.finished = true
+			return nil
+		},
+	}
+}
+
+func TestFlowHandle(t *testing.T) {
+	p := i18n.New(i18n.Russian)
+	tests := []struct {
+		name  string
+		state order
+		// gotoStep is asked before the message if set.
+		gotoStep string
+		text     string
+		want     order
+		// wantSent is the message Handle sends, "" if it sends none.
+		wantSent string
+	}{
+		{
+			name:     "back on the first step",
+			state:    order{position: "source"},
+			text:     p.T(i18n.ButtonBack),
+			want:     order{position: "source"},
+			wantSent: p.T(i18n.AskSource),
+		},
+		{
+			name:     "back",
+			state:    order{position: "destination", source: "Тверская, 1"},
+			text:     p.T(i18n.ButtonBack),
+			want:     order{position: "time", source: "Тверская, 1"},
+			wantSent: p.T(i18n.AskTime),
+		},
+		{
+			name:     "skip a required step",
+			state:    order{position: "source"},
+			text:     p.T(i18n.ButtonSkip),
+			want:     order{position: "source"},
+			wantSent: p.T(i18n.AskSource),
+		},
+		{
+			name:     "skip an optional step",
+			state:    order{position: "time", source: "Тверская, 1"},
+			text:     p.T(i18n.ButtonSkip),
+			want:     order{position: "destination", source: "Тверская, 1"},
+			wantSent: p.T(i18n.AskDestination),
+		},
+		{
+			name:  "shortcut",
+			state: order{},
+			text:  " Дом ",
+			want: order{
+				position:    "time",
+				source:      home.Input.Text,
+				sourcePoint: *home.Input.Point,
+			},
+			wantSent: p.T(i18n.AskTime),
+		},
+		{
+			name:     "typed address that is not a shortcut",
+			state:    order{},
+			text:     "Домодедово",
+			want:     order{position: "time", source: "Домодедово"},
+			wantSent: p.T(i18n.AskTime),
+		},
+		{
+			name:     "goto then next skips filled steps",
+			state:    order{source: "Тверская, 1", sourcePoint: *home.Input.Point, time: "18:00"},
+			gotoStep: "source",
+			text:     "Арбат, 10",
+			want:     order{position: "destination", source: "Арбат, 10", time: "18:00"},
+			wantSent: p.T(i18n.AskDestination),
+		},
+		{
+			name:  "last step finishes",
+			state: order{position: "destination", source: "Тверская, 1"},
+			text:  "Шереметьево",
+			want:  order{position: "destination", source: "Тверская, 1", destination: "Шереметьево", finished: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			c, api := newConversation(t)
+			flow := newFlow()
+			state := tt.state
+
+			if tt.gotoStep != "" {
+				if err := flow.Goto(ctx, c, &state, tt.gotoStep); err != nil {
+					t.Fatalf("Goto(%s): %v", tt.gotoStep, err)
+				}
+			}
+			before := len(api.sent())
+			if err := flow.Handle(ctx, c, &state, &telego.Message{Text: tt.text}); err != nil {
+				t.Fatalf("Handle(%q): %v", tt.text, err)
+			}
+			if state != tt.want {
+				t.Errorf("state = %+v, want %+v", state, tt.want)
+			}
+			var want []string
+			if tt.wantSent != "" {
+				want = []string{tt.wantSent}
+			}
+			if got := api.sent()[before:]; !equal(got, want) {
+				t.Errorf("sent %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,129 @@
+package dispatch
+
+import (
+	"context"
+	"expvar"
+	"sync"
+	"time"
+
+	"github.com/mymmrac/telego"
+)
+
+type Handler func(ctx context.Context, update telego.Update)
+
+// Dispatcher processes updates on a fixed number of workers. Updates are
+// sharded by the user (or chat) they come from, so updates of one user are
+// handled in order while different users are handled in parallel.
+//
+// Every worker has a bounded queue. When a queue is full Run blocks, which
+// in turn stops reading from the long polling or webhook channel.
+type Dispatcher struct {
+	handle  Handler
+	queues  []chan telego.Update
+	metrics *expvar.Map
+}
+
+// New creates a dispatcher and publishes its metrics as the expvar
+// "dispatch_<name>". It must be called once per name.
+func New(name string, workers int, queueSize int, handle Handler) *Dispatcher {
+	if workers < 1 {
+		workers = 1
+	}
+	d := &Dispatcher{
+		handle:  handle,
+		queues:  make([]chan telego.Update, workers),
+		metrics: expvar.NewMap("dispatch_" + name),
+	}
+	for i := range d.queues {
+		d.queues[i] = make(chan telego.Update, queueSize)
+	}
+	d.metrics.Set("queued", expvar.Func(func() any {
+		queued := 0
+		for _, q := range d.queues {
+			queued += len(q)
+		}
+		return queued
+	}))
+	d.metrics.Set("capacity", expvar.Func(func() any {
+		return workers * queueSize
+	}))
+	return d
+}
+
+// Run dispatches updates until the channel is closed and then waits for
+// the queued ones to be processed.
+func (d *Dispatcher) Run(updates <-chan telego.Update) {
+	wg := &sync.WaitGroup{}
+	for _, q := range d.queues {
+		wg.Add(1)
+		go func(q <-chan telego.Update) {
+			defer wg.Done()
+			d.work(q)
+		}(q)
+	}
+
+	for update := range updates {
+		d.enqueue(update)
+	}
+	for _, q := range d.queues {
+		close(q)
+	}
+	wg.Wait()
+}
+
+func (d *Dispatcher) enqueue(update telego.Update) {
+	q := d.queues[shard(key(update), len(d.queues))]
+	d.metrics.Add("received", 1)
+	select {
+	case q <- update:
+		return
+	default:
+	}
+	// The queue is full: wait for the worker and account for the time the
+	// sender was held back.
+	start := time.Now()
+	q <- update
+	d.metrics.Add("blocked", 1)
+	d.metrics.Add("blocked_ms", time.Since(start).Milliseconds())
+}
+
+func (d *Dispatcher) work(q <-chan telego.Update) {
+	for update := range q {
+		start := time.Now()
+		d.handle(context.Background(), update)
+		d.metrics.Add("processed", 1)
+		d.metrics.Add("processing_ms", time.Since(start).Milliseconds())
+	}
+}
+
+// key identifies whose update it is. The sender is preferred over the chat
+// so that messages and button presses of one user stay ordered; in private
+// chats both are the same.
+func key(update telego.Update) int64 {
+	switch {
+	case update.Message != nil:
+		return messageKey(update.Message)
+	case update.EditedMessage != nil:
+		return messageKey(update.EditedMessage)
+	case update.ChannelPost != nil:
+		return update.ChannelPost.Chat.ID
+	case update.CallbackQuery != nil:
+		return update.CallbackQuery.From.ID
+	}
+	return 0
+}
+
+func messageKey(message *telego.Message) int64 {
+	if message.From != nil {
+		return message.From.ID
+	}
+	return message.Chat.ID
+}
+
+func shard(key int64, n int) int {
+	s := int(key % int64(n))
+	if s < 0 {
+		s = -s
+	}
+	return s
+}
@@ -0,0 +1,147 @@
+package dispatch
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mymmrac/telego"
+)
+
+var dispatchers atomic.Int64
+
+// newDispatcher names every dispatcher apart, as expvar refuses to publish a
+// name twice and tests may run more than once.
+func newDispatcher(workers int, queueSize int, handle Handler) *Dispatcher {
+	return New(fmt.Sprintf("test_%d", dispatchers.Add(1)), workers, queueSize, handle)
+}
+
+func message(updateID int, userID int64) telego.Update {
+	return telego.Update{
+		UpdateID: updateID,
+		Message: &telego.Message{
+			From: &telego.User{ID: userID},
+			Chat: telego.Chat{ID: userID},
+		},
+	}
+}
+
+func TestRunKeepsUserOrder(t *testing.T) {
+	const (
+		users          = 7
+		updatesPerUser = 50
+	)
+	mu := sync.Mutex{}
+	handled := make(map[int64][]int)
+	d := newDispatcher(3, 2, func(_ context.Context, update telego.Update) {
+		if update.UpdateID%5 == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		userID := update.Message.From.ID
+		handled[userID] = append(handled[userID], update.UpdateID)
+	})
+
+	updates := make(chan telego.Update)
+	go func() {
+		for i := 0; i < users*updatesPerUser; i++ {
+			updates <- message(i, int64(i%users))
+		}
+		close(updates)
+	}()
+	// Run returns only after every queued update is handled.
+	d.Run(updates)
+
+	for userID := int64(0); userID < users; userID++ {
+		ids := handled[userID]
+		if len(ids) != updatesPerUser {
+			t.Fatalf("user %d: handled %d updates, want %d", userID, len(ids), updatesPerUser)
+		}
+		for i := 1; i < len(ids); i++ {
+			if ids[i] < ids[i-1] {
+				t.Fatalf("user %d: update %d handled after %d", userID, ids[i], ids[i-1])
+			}
+		}
+	}
+}
+
+func TestRunHandlesUsersInParallel(t *testing.T) {
+	released := make(chan struct{})
+	d := newDispatcher(2, 1, func(_ context.Context, update telego.Update) {
+		if update.Message.From.ID == 1 {
+			// Waits for the update of the other user, which would never
+			// come if the users shared a worker.
+			select {
+			case <-released:
+			case <-time.After(5 * time.Second):
+				t.Error("update of user 2 was not handled while user 1 was busy")
+			}
+			return
+		}
+		close(released)
+	})
+
+	updates := make(chan telego.Update, 2)
+	updates <- message(1, 1)
+	updates <- message(2, 2)
+	close(updates)
+	d.Run(updates)
+}
+
+func TestKey(t *testing.T) {
+	tests := []struct {
+		name   string
+		update telego.Update
+		want   int64
+	}{
+		{name: "message", update: message(1, 10), want: 10},
+		{
+			name: "group message",
+			update: telego.Update{Message: &telego.Message{
+				From: &telego.User{ID: 10},
+				Chat: telego.Chat{ID: -100},
+			}},
+			want: 10,
+		},
+		{
+			name:   "message without sender",
+			update: telego.Update{Message: &telego.Message{Chat: telego.Chat{ID: -100}}},
+			want:   -100,
+		},
+		{
+			name:   "edited message",
+			update: telego.Update{EditedMessage: &telego.Message{From: &telego.User{ID: 11}}},
+			want:   11,
+		},
+		{
+			name:   "channel post",
+			update: telego.Update{ChannelPost: &telego.Message{Chat: telego.Chat{ID: -200}}},
+			want:   -200,
+		},
+		{
+			name:   "callback",
+			update: telego.Update{CallbackQuery: &telego.CallbackQuery{From: telego.User{ID: 12}}},
+			want:   12,
+		},
+		{name: "other", update: telego.Update{}, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := key(tt.update); got != tt.want {
+				t.Errorf("key() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShard(t *testing.T) {
+	for _, k := range []int64{0, 1, 7, -1, -100123456789} {
+		if s := shard(k, 4); s < 0 || s >= 4 {
+			t.Errorf("shard(%d, 4) = %d, want in [0, 4)", k, s)
+		}
+	}
+}
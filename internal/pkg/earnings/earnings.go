@@ -0,0 +1,85 @@
+package earnings
+
+import (
+	"time"
+
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/models"
+)
+
+type Summary struct {
+	Orders     int
+	Driving    time.Duration
+	Gross      models.Money
+	Commission models.Money
+	// Unpaid is the part of the commission the driver has not paid yet.
+	Unpaid models.Money
+}
+
+type Report struct {
+	Today Summary
+	Week  Summary
+	Month Summary
+}
+
+type Periods struct {
+	Today time.Time
+	Week  time.Time
+	Month time.Time
+}
+
+// PeriodsAt returns the starts of the day, the week (from Monday) and the
+// month of now in the city's timezone.
+func PeriodsAt(now time.Time, loc *time.Location) Periods {
+	now = now.In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	weekday := (int(today.Weekday()) + 6) % 7
+	return Periods{
+		Today: today,
+		Week:  today.AddDate(0, 0, -weekday),
+		Month: time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, loc),
+	}
+}
+
+// Since is the start of the earliest period, the week may begin in the
+// previous month.
+func (p Periods) Since() time.Time {
+	if p.Week.Before(p.Month) {
+		return p.Week
+	}
+	return p.Month
+}
+
+// Commission is percent of the fare, rounded to the kopeck.
+func Commission(fare models.Money, percent int64) models.Money {
+	return models.Money((int64(fare)*percent + 50) / 100)
+}
+
+// Summarize buckets the trips by the time they finished.
+func Summarize(trips []models.DriverTrip, periods Periods, percent int64) Report {
+	var r Report
+	for i := range trips {
+		trip := &trips[i]
+		fare := trip.Fare()
+		commission := Commission(fare, percent)
+		for _, bucket := range []struct {
+			summary *Summary
+			since   time.Time
+		}{
+			{&r.Today, periods.Today},
+			{&r.Week, periods.Week},
+			{&r.Month, periods.Month},
+		} {
+			if trip.FinishedAt.Before(bucket.since) {
+				continue
+			}
+			bucket.summary.Orders++
+			bucket.summary.Driving += trip.Duration()
+			bucket.summary.Gross += fare
+			bucket.summary.Commission += commission
+			if !trip.CommissionPaid {
+				bucket.summary.Unpaid += commission
+			}
+		}
+	}
+	return r
+}
@@ -0,0 +1,136 @@
+package earnings
+
+import (
+	"testing"
+	"time"
+
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/models"
+)
+
+func moscow(t *testing.T) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		t.Fatalf("time.LoadLocation: %v", err)
+	}
+	return loc
+}
+
+func TestPeriodsAt(t *testing.T) {
+	loc := moscow(t)
+	day := func(month time.Month, day int) time.Time {
+		return time.Date(2023, month, day, 0, 0, 0, 0, loc)
+	}
+	tests := []struct {
+		name string
+		now  time.Time
+		want Periods
+	}{
+		{
+			name: "wednesday",
+			now:  time.Date(2023, time.May, 17, 13, 0, 0, 0, loc),
+			want: Periods{Today: day(time.May, 17), Week: day(time.May, 15), Month: day(time.May, 1)},
+		},
+		{
+			name: "sunday",
+			now:  time.Date(2023, time.May, 21, 23, 59, 0, 0, loc),
+			want: Periods{Today: day(time.May, 21), Week: day(time.May, 15), Month: day(time.May, 1)},
+		},
+		{
+			name: "week from the previous month",
+			now:  time.Date(2023, time.June, 1, 9, 0, 0, 0, loc),
+			want: Periods{Today: day(time.June, 1), Week: day(time.May, 29), Month: day(time.June, 1)},
+		},
+		{
+			// 22:30 UTC on May 31 is already June 1 in Moscow.
+			name: "utc evening",
+			now:  time.Date(2023, time.May, 31, 22, 30, 0, 0, time.UTC),
+			want: Periods{Today: day(time.June, 1), Week: day(time.May, 29), Month: day(time.June, 1)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := PeriodsAt(tt.now, loc)
+			if !got.Today.Equal(tt.want.Today) || !got.Week.Equal(tt.want.Week) || !got.Month.Equal(tt.want.Month) {
+				t.Errorf("PeriodsAt(%v) = %+v, want %+v", tt.now, got, tt.want)
+			}
+		})
+	}
+
+	periods := PeriodsAt(time.Date(2023, time.June, 1, 9, 0, 0, 0, loc), loc)
+	if since := periods.Since(); !since.Equal(day(time.May, 29)) {
+		t.Errorf("Since() = %v, want the start of the week", since)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	loc := moscow(t)
+	at := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2023, month, day, hour, minute, 0, 0, loc)
+	}
+	started := func(month time.Month, day, hour, minute int) *time.Time {
+		s := at(month, day, hour, minute)
+		return &s
+	}
+	money := func(m models.Money) *models.Money { return &m }
+	// Thursday, the week started on May 29.
+	periods := PeriodsAt(at(time.June, 1, 12, 0), loc)
+
+	trips := []models.DriverTrip{
+		{
+			// An hour today, the commission is paid.
+			OrderID:        1,
+			Price:          money(models.Rubles(1000)),
+			StartedAt:      started(time.June, 1, 9, 0),
+			FinishedAt:     at(time.June, 1, 10, 0),
+			CommissionPaid: true,
+		},
+		{
+			// Finished without being started; the commission is rounded.
+			OrderID:    2,
+			Price:      money(333),
+			FinishedAt: at(time.June, 1, 11, 0),
+		},
+		{
+			// Two and a half hours this week: the first one and two more.
+			OrderID:      3,
+			Price:        money(models.Rubles(1000)),
+			PricePerHour: money(models.Rubles(500)),
+			StartedAt:    started(time.May, 30, 17, 30),
+			FinishedAt:   at(time.May, 30, 20, 0),
+		},
+		{
+			// On the first minute of the week, without a price.
+			OrderID:    4,
+			FinishedAt: at(time.May, 29, 0, 0),
+		},
+	}
+
+	got := Summarize(trips, periods, 15)
+
+	today := Summary{
+		Orders:     2,
+		Driving:    time.Hour,
+		Gross:      models.Rubles(1000) + 333,
+		Commission: models.Rubles(150) + 50,
+		Unpaid:     50,
+	}
+	want := Report{
+		Today: today,
+		Week: Summary{
+			Orders:     4,
+			Driving:    3*time.Hour + 30*time.Minute,
+			Gross:      models.Rubles(3000) + 333,
+			Commission: models.Rubles(450) + 50,
+			Unpaid:     models.Rubles(300) + 50,
+		},
+		Month: today,
+	}
+	if got != want {
+		t.Errorf("Summarize() = %+v, want %+v", got, want)
+	}
+
+	if empty := Summarize(nil, periods, 15); empty != (Report{}) {
+		t.Errorf("Summarize(nil) = %+v, want an empty report", empty)
+	}
+}
@@ -0,0 +1,144 @@
+package i18n
+
+var english = map[Key]Message{
+	StartGreeting:       {Other: "Hello! We are happy to take your order."},
+	ButtonCreateOrder:   {Other: "New order"},
+	AskSource:           {Other: "Where should the driver pick you up? You can also send a location or a place on the map."},
+	ButtonSendLocation:  {Other: "Send my location"},
+	AskAddress:          {Other: "Type the address or send a point on the map via 📎"},
+	PointOnMap:          {Other: "Point on the map (%s)"},
+	AskTime:             {Other: "What time should the driver arrive?"},
+	AskDestination:      {Other: "Where are you going?"},
+	AskPhone:            {Other: "What phone number can the driver call you on?"},
+	NoOrder:             {Other: "You have no order"},
+	OrderAwaitingDriver: {Other: "Your order is being processed, we will find you a driver soon...\nPrice: %s"},
+	TimeUnrecognized:    {Other: "Sorry, I did not understand the time. Please write it like «23:15» or «15.05 18:00»."},
+	TimeChooseOption:    {Other: "Please specify: %s?"},
+	TimeOptionLayout:    {Other: "02.01 at 15:04"},
+	TimeOptionSeparator: {Other: " or "},
+	TimeAmbiguous:       {Other: "Please specify what time the driver should arrive."},
+	TimeInPast:          {Other: "This time has already passed. What time should the driver arrive?"},
+	DriverWaiting:       {Other: "Your driver is waiting for you at: %s"},
+	TripFinished:        {Other: "Your trip is over. Thank you for riding with us!"},
+	DriverFound:         {Other: "We found you a driver. They will contact you soon.\nYou can write to the driver right in this chat."},
+	ButtonCancelOrder:   {Other: "Cancel order"},
+	NoActiveOrder:       {Other: "You have no active order"},
+	OrderInProgress:     {Other: "You already have an active order. You can create a new one once it is finished or cancelled."},
+	CannotCancel:        {Other: "The order cannot be cancelled anymore: it is %s"},
+	OrderCancelled:      {Other: "Order cancelled"},
+	OrderCancelledFee:   {Other: "Order cancelled. A driver had already been assigned, so there is a cancellation fee. Pay: %s"},
+	PaymentOrder:        {Other: "Payment"},
+	PaymentLateFee:      {Other: "Late cancellation fee"},
+	MessageFromDriver:   {Other: "Message from the driver"},
+	OrderPaid:           {Other: "Order MOSCOW-%04d has been paid"},
+	OrderReview:         {Other: "Please check your order:\nFrom: %s\nTo: %s\nTime: %s\nPhone: %s"},
+	ButtonEditSource:    {Other: "Change pickup address"},
+	ButtonEditDest:      {Other: "Change destination"},
+	ButtonEditTime:      {Other: "Change time"},
+	ButtonEditPhone:     {Other: "Change phone"},
+	ButtonConfirm:       {Other: "Confirm"},
+	PhoneInvalid:        {Other: "This does not look like a phone number. Tap «Share phone number» or type it like +7 916 123-45-67."},
+	PhoneSaved:          {Other: "Contact phone: %s"},
+	RateTrip:            {Other: "Please rate your trip:"},
+	AlreadyRated:        {Other: "You have already rated this trip"},
+	TripNotFinished:     {Other: "The trip is not finished yet"},
+	AskComment:          {Other: "Thank you for the rating! You can also write a comment about the trip."},
+	FeedbackThanks:      {Other: "Thank you for the feedback!"},
+	DriverRating: {
+		One:   "Driver rating: %.1f ★ (%d rating)",
+		Other: "Driver rating: %.1f ★ (%d ratings)",
+	},
+	NoOrders:          {Other: "You have no orders yet."},
+	OrderHistory:      {Other: "Your orders, page %d:"},
+	OrderHistoryItem:  {Other: "#%d · %s · %s\n%s → %s"},
+	ButtonRepeatOrder: {Other: "Repeat #%d"},
+	ButtonNewerOrders: {Other: "← Newer"},
+	ButtonOlderOrders: {Other: "Older →"},
+	ProfileCard:       {Other: "Profile\nName: %s\nPhone: %s"},
+	ProfileNotSet:     {Other: "not set"},
+	ProfilePlaces:     {Other: "Saved places:"},
+	ProfilePlace:      {Other: "• %s — %s"},
+	NoPlaces:          {Other: "No saved places yet. After a trip the bot will offer to save its addresses."},
+	ButtonEditName:    {Other: "Change name"},
+	ButtonDeletePlace: {Other: "Delete «%s»"},
+	AskName:           {Other: "What should we call you?"},
+	ProfileSaved:      {Other: "Profile saved"},
+	SavePlaces:        {Other: "Save the addresses of the trip to pick them with one tap?"},
+	ButtonSavePlace:   {Other: "Save «%s»"},
+	AskPlaceName:      {Other: "How should we name this address? Pick an option or type your own name."},
+	PlaceHome:         {Other: "Home"},
+	PlaceWork:         {Other: "Work"},
+	PlaceSaved:        {Other: "The address is saved as «%s»"},
+	PlaceNotSaved:     {Other: "The address is not saved"},
+
+	DriverStart:         {Other: "Hello! To take orders, share the phone number you gave in your application."},
+	ButtonShareContact:  {Other: "Share phone number"},
+	ShareOwnContact:     {Other: "Please share your own phone number."},
+	ApplicationNotFound: {Other: "No application with this number. Please apply on the website."},
+	ApplicationLinked:   {Other: "The application with this number is linked to another Telegram account. Please contact the administrator."},
+	ApplicationApproved: {Other: "Your application is approved, now you can take orders."},
+	ApplicationRejected: {Other: "Your application is rejected."},
+	ApplicationPending:  {Other: "Thank you! We will let you know once your application is approved."},
+	OnlyApprovedDrivers: {Other: "Only approved drivers can take orders. Send /start to the bot in private messages."},
+	OrderAlreadyTaken:   {Other: "The order is already taken"},
+	ActionUnavailable:   {Other: "Not available: the order is %s"},
+	AskStartTrip:        {Other: "Start the trip once the customer is in the car"},
+	ButtonStartTrip:     {Other: "Start trip"},
+	TripInProgress:      {Other: "Trip in progress"},
+	ButtonFinishTrip:    {Other: "Finish trip"},
+	DriverTripFinished:  {Other: "The order is finished."},
+	CommissionToPay:     {Other: "Service commission for the order: %s. Pay: %s"},
+	CommissionPaid:      {Other: "The commission for order MOSCOW-%04d is paid. Thank you!"},
+	PaymentCommission:   {Other: "Service commission for order MOSCOW-%04d"},
+	ButtonArrived:       {Other: "I have arrived"},
+	DriverRelayHint:     {Other: "The customer's phone is hidden, you can write to them in this chat."},
+	MessageFromCustomer: {Other: "Message from the customer of order MOSCOW-%04d"},
+	DriverNoActiveOrder: {Other: "You have no active order, the message was not delivered."},
+	RelayClosed:         {Other: "This order has no chat with the customer, please call the phone number from the order."},
+	CustomerCancelled:   {Other: "The customer cancelled order MOSCOW-%04d"},
+	VenueSource:         {Other: "Pickup"},
+	VenueDestination:    {Other: "Destination"},
+	StatsOnlyDrivers:    {Other: "Statistics are available to drivers only."},
+	StatsToday:          {Other: "Today"},
+	StatsWeek:           {Other: "This week"},
+	StatsMonth:          {Other: "This month"},
+	StatsOrders: {
+		One:   "%d order completed",
+		Other: "%d orders completed",
+	},
+	StatsDriving: {Other: "Driving: %dh %02dm"},
+	StatsMoney:   {Other: "Gross fares: %s\nService commission: %s\nUnpaid commission: %s"},
+
+	RelayUnsupported: {Other: "Only text, photos and locations can be sent."},
+	ChooseLanguage:   {Other: "Choose your language"},
+	LanguageChosen:   {Other: "Bot language: English"},
+	LanguageName:     {Other: "English"},
+	ButtonBack:       {Other: "⬅️ Back"},
+	ButtonSkip:       {Other: "Skip"},
+	ChooseOption:     {Other: "Please pick one of the options on the keyboard."},
+	AskText:          {Other: "Please answer with text."},
+	ButtonOutdated:   {Other: "This button is outdated, please use the buttons of a newer message."},
+
+	ButtonTakeOrder:    {Other: "Take the order"},
+	OrderTakenBy:       {Other: "Taken (%d)"},
+	OrderCancelledMark: {Other: "Cancelled by the customer"},
+	ApplicationNew:     {Other: "New driver application\nPhone: %s\nName: %s\nExperience: %s"},
+	ButtonApprove:      {Other: "Approve"},
+	ButtonReject:       {Other: "Reject"},
+	ResolutionApproved: {Other: "Approved"},
+	ResolutionRejected: {Other: "Rejected"},
+	LowRating:          {Other: "Low rating %d ★ for order MOSCOW-%04d, driver %d"},
+	LowRatingComment:   {Other: "Comment on the rating of order MOSCOW-%04d:\n%s"},
+	OrderDriverChat:    {Other: "ID: MOSCOW-%04d\nFrom: %s\nTo: %s\nTime: %s"},
+	OrderPrivate:       {Other: "ID: MOSCOW-%04d\nFrom: %s\nTo: %s\nTime: %s\nPhone: %s"},
+	OrderPrice:         {Other: "Price: %s"},
+	OrderPricePerHour:  {Other: "Price: %s for the first hour, then %s/hour"},
+
+	OrderStatusDraft:     {Other: "not placed yet"},
+	OrderStatusAwaiting:  {Other: "waiting for a driver"},
+	OrderStatusAssigned:  {Other: "already taken"},
+	OrderStatusArrived:   {Other: "waiting for the customer"},
+	OrderStatusProgress:  {Other: "already on the way"},
+	OrderStatusFinished:  {Other: "already finished"},
+	OrderStatusCancelled: {Other: "cancelled"},
+}
@@ -0,0 +1,132 @@
+package i18n
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Lang string
+
+const (
+	Russian Lang = "ru"
+	English Lang = "en"
+
+	// Default is used for group chats and for users whose language is
+	// unknown.
+	Default = Russian
+)
+
+var Langs = []Lang{Russian, English}
+
+type Key string
+
+// Message is a text in one language. Texts without a number only set
+// Other; the rest of the forms are picked by the plural rules of the
+// language and fall back to Other.
+type Message struct {
+	One   string
+	Few   string
+	Many  string
+	Other string
+}
+
+var catalogs = map[Lang]map[Key]Message{
+	Russian: russian,
+	English: english,
+}
+
+var pluralRules = map[Lang]func(n int, m Message) string{
+	Russian: russianPlural,
+	English: englishPlural,
+}
+
+// Lookup returns the language stored for a user, or Default if it is not
+// supported.
+func Lookup(lang string) Lang {
+	if _, ok := catalogs[Lang(lang)]; ok {
+		return Lang(lang)
+	}
+	return Default
+}
+
+// FromTelegram picks the language by the IETF tag Telegram reports for a
+// user. Russian speakers of the neighbouring countries get Russian,
+// everyone else English.
+func FromTelegram(languageCode string) Lang {
+	if languageCode == "" {
+		return Default
+	}
+	tag, _, _ := strings.Cut(strings.ToLower(languageCode), "-")
+	switch tag {
+	case "ru", "uk", "be", "kk":
+		return Russian
+	}
+	return English
+}
+
+type Printer struct {
+	lang Lang
+}
+
+func New(lang Lang) Printer {
+	return Printer{lang: Lookup(string(lang))}
+}
+
+func (p Printer) Lang() Lang {
+	return p.lang
+}
+
+// T formats the message with the args like fmt.Sprintf.
+func (p Printer) T(key Key, args ...any) string {
+	return p.format(p.message(key).Other, args)
+}
+
+// N is like T but picks the plural form for n. The number has to be passed
+// in args as well if the text shows it.
+func (p Printer) N(key Key, n int, args ...any) string {
+	return p.format(pluralRules[p.lang](n, p.message(key)), args)
+}
+
+func (p Printer) message(key Key) Message {
+	if m, ok := catalogs[p.lang][key]; ok {
+		return m
+	}
+	if m, ok := catalogs[Default][key]; ok {
+		return m
+	}
+	return Message{Other: string(key)}
+}
+
+func (p Printer) format(text string, args []any) string {
+	if len(args) == 0 {
+		return text
+	}
+	return fmt.Sprintf(text, args...)
+}
+
+func form(text string, fallback string) string {
+	if text == "" {
+		return fallback
+	}
+	return text
+}
+
+func russianPlural(n int, m Message) string {
+	if n < 0 {
+		n = -n
+	}
+	switch {
+	case n%10 == 1 && n%100 != 11:
+		return form(m.One, m.Other)
+	case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+		return form(m.Few, m.Other)
+	}
+	return form(m.Many, m.Other)
+}
+
+func englishPlural(n int, m Message) string {
+	if n == 1 {
+		return form(m.One, m.Other)
+	}
+	return m.Other
+}
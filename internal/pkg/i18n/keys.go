@@ -0,0 +1,159 @@
+package i18n
+
+// Customer bot.
+const (
+	StartGreeting       Key = "start_greeting"
+	ButtonCreateOrder   Key = "button_create_order"
+	AskSource           Key = "ask_source"
+	ButtonSendLocation  Key = "button_send_location"
+	AskAddress          Key = "ask_address"
+	PointOnMap          Key = "point_on_map"
+	AskTime             Key = "ask_time"
+	AskDestination      Key = "ask_destination"
+	AskPhone            Key = "ask_phone"
+	NoOrder             Key = "no_order"
+	OrderAwaitingDriver Key = "order_awaiting_driver"
+	TimeUnrecognized    Key = "time_unrecognized"
+	TimeChooseOption    Key = "time_choose_option"
+	TimeOptionLayout    Key = "time_option_layout"
+	TimeOptionSeparator Key = "time_option_separator"
+	TimeAmbiguous       Key = "time_ambiguous"
+	TimeInPast          Key = "time_in_past"
+	DriverWaiting       Key = "driver_waiting"
+	TripFinished        Key = "trip_finished"
+	DriverFound         Key = "driver_found"
+	ButtonCancelOrder   Key = "button_cancel_order"
+	NoActiveOrder       Key = "no_active_order"
+	OrderInProgress     Key = "order_in_progress"
+	CannotCancel        Key = "cannot_cancel"
+	OrderCancelled      Key = "order_cancelled"
+	OrderCancelledFee   Key = "order_cancelled_fee"
+	PaymentOrder        Key = "payment_order"
+	PaymentLateFee      Key = "payment_late_fee"
+	MessageFromDriver   Key = "message_from_driver"
+	OrderPaid           Key = "order_paid"
+	OrderReview         Key = "order_review"
+	ButtonEditSource    Key = "button_edit_source"
+	ButtonEditDest      Key = "button_edit_destination"
+	ButtonEditTime      Key = "button_edit_time"
+	ButtonEditPhone     Key = "button_edit_phone"
+	ButtonConfirm       Key = "button_confirm"
+	PhoneInvalid        Key = "phone_invalid"
+	PhoneSaved          Key = "phone_saved"
+	RateTrip            Key = "rate_trip"
+	AlreadyRated        Key = "already_rated"
+	TripNotFinished     Key = "trip_not_finished"
+	AskComment          Key = "ask_comment"
+	FeedbackThanks      Key = "feedback_thanks"
+	// DriverRating takes the average and the number of ratings.
+	DriverRating Key = "driver_rating"
+	NoOrders     Key = "no_orders"
+	// OrderHistory takes the page number.
+	OrderHistory Key = "order_history"
+	// OrderHistoryItem takes the order number, date, status, source and
+	// destination.
+	OrderHistoryItem  Key = "order_history_item"
+	ButtonRepeatOrder Key = "button_repeat_order"
+	ButtonNewerOrders Key = "button_newer_orders"
+	ButtonOlderOrders Key = "button_older_orders"
+	// ProfileCard takes the name and the phone.
+	ProfileCard   Key = "profile_card"
+	ProfileNotSet Key = "profile_not_set"
+	ProfilePlaces Key = "profile_places"
+	// ProfilePlace takes the name and the address of a saved place.
+	ProfilePlace      Key = "profile_place"
+	NoPlaces          Key = "no_places"
+	ButtonEditName    Key = "button_edit_name"
+	ButtonDeletePlace Key = "button_delete_place"
+	AskName           Key = "ask_name"
+	ProfileSaved      Key = "profile_saved"
+	SavePlaces        Key = "save_places"
+	ButtonSavePlace   Key = "button_save_place"
+	AskPlaceName      Key = "ask_place_name"
+	PlaceHome         Key = "place_home"
+	PlaceWork         Key = "place_work"
+	PlaceSaved        Key = "place_saved"
+	PlaceNotSaved     Key = "place_not_saved"
+)
+
+// Driver bot.
+const (
+	DriverStart         Key = "driver_start"
+	ButtonShareContact  Key = "button_share_contact"
+	ShareOwnContact     Key = "share_own_contact"
+	ApplicationNotFound Key = "application_not_found"
+	ApplicationLinked   Key = "application_linked"
+	ApplicationApproved Key = "application_approved"
+	ApplicationRejected Key = "application_rejected"
+	ApplicationPending  Key = "application_pending"
+	OnlyApprovedDrivers Key = "only_approved_drivers"
+	OrderAlreadyTaken   Key = "order_already_taken"
+	ActionUnavailable   Key = "action_unavailable"
+	AskStartTrip        Key = "ask_start_trip"
+	ButtonStartTrip     Key = "button_start_trip"
+	TripInProgress      Key = "trip_in_progress"
+	ButtonFinishTrip    Key = "button_finish_trip"
+	DriverTripFinished  Key = "driver_trip_finished"
+	// CommissionToPay takes the commission and the payment URL.
+	CommissionToPay Key = "commission_to_pay"
+	// CommissionPaid takes the order number.
+	CommissionPaid      Key = "commission_paid"
+	PaymentCommission   Key = "payment_commission"
+	ButtonArrived       Key = "button_arrived"
+	DriverRelayHint     Key = "driver_relay_hint"
+	MessageFromCustomer Key = "message_from_customer"
+	DriverNoActiveOrder Key = "driver_no_active_order"
+	RelayClosed         Key = "relay_closed"
+	CustomerCancelled   Key = "customer_cancelled"
+	VenueSource         Key = "venue_source"
+	VenueDestination    Key = "venue_destination"
+	StatsOnlyDrivers    Key = "stats_only_drivers"
+	StatsToday          Key = "stats_today"
+	StatsWeek           Key = "stats_week"
+	StatsMonth          Key = "stats_month"
+	// StatsOrders takes the number of orders.
+	StatsOrders  Key = "stats_orders"
+	StatsDriving Key = "stats_driving"
+	// StatsMoney takes the gross fares, the commission and its unpaid part.
+	StatsMoney Key = "stats_money"
+)
+
+// Both bots.
+const (
+	RelayUnsupported Key = "relay_unsupported"
+	ChooseLanguage   Key = "choose_language"
+	LanguageChosen   Key = "language_chosen"
+	// LanguageName is the name of the language in itself.
+	LanguageName Key = "language_name"
+	ButtonBack   Key = "button_back"
+	ButtonSkip   Key = "button_skip"
+	ChooseOption Key = "choose_option"
+	AskText      Key = "ask_text"
+	// ButtonOutdated answers a button the bot can no longer read.
+	ButtonOutdated Key = "button_outdated"
+)
+
+// Drivers and admin chats.
+const (
+	ButtonTakeOrder      Key = "button_take_order"
+	OrderTakenBy         Key = "order_taken_by"
+	OrderCancelledMark   Key = "order_cancelled_mark"
+	ApplicationNew       Key = "application_new"
+	ButtonApprove        Key = "button_approve"
+	ButtonReject         Key = "button_reject"
+	ResolutionApproved   Key = "resolution_approved"
+	ResolutionRejected   Key = "resolution_rejected"
+	LowRating            Key = "low_rating"
+	LowRatingComment     Key = "low_rating_comment"
+	OrderDriverChat      Key = "order_driver_chat"
+	OrderPrivate         Key = "order_private"
+	OrderPrice           Key = "order_price"
+	OrderPricePerHour    Key = "order_price_per_hour"
+	OrderStatusDraft     Key = "order_status_draft"
+	OrderStatusAwaiting  Key = "order_status_awaiting_driver"
+	OrderStatusAssigned  Key = "order_status_assigned"
+	OrderStatusArrived   Key = "order_status_driver_arrived"
+	OrderStatusProgress  Key = "order_status_in_progress"
+	OrderStatusFinished  Key = "order_status_finished"
+	OrderStatusCancelled Key = "order_status_cancelled"
+)
@@ -0,0 +1,148 @@
+package i18n
+
+var russian = map[Key]Message{
+	StartGreeting:       {Other: "Здравствуйте, мы рады принять ваш заказ!"},
+	ButtonCreateOrder:   {Other: "Создать заказ"},
+	AskSource:           {Other: "Укажите точку подачи, водитель приедет по указанному адресу. Можно отправить геопозицию или место на карте."},
+	ButtonSendLocation:  {Other: "Отправить геопозицию"},
+	AskAddress:          {Other: "Напишите адрес текстом или отправьте точку на карте через 📎"},
+	PointOnMap:          {Other: "Точка на карте (%s)"},
+	AskTime:             {Other: "Уточните, во сколько к вам приехать?"},
+	AskDestination:      {Other: "Укажи конечную точку подачи"},
+	AskPhone:            {Other: "Укажите Ваш номер телефона для связи?"},
+	NoOrder:             {Other: "У тебя нет заказа"},
+	OrderAwaitingDriver: {Other: "Заказ в обработке, мы скоро найдём Вам водителя...\nСтоимость: %s"},
+	TimeUnrecognized:    {Other: "Не получилось понять время. Напишите, например: «сейчас», «через 30 минут», «в 23:15», «завтра в 9» или «15.05 18:00»."},
+	TimeChooseOption:    {Other: "Уточните, пожалуйста: %s?"},
+	TimeOptionLayout:    {Other: "02.01 в 15:04"},
+	TimeOptionSeparator: {Other: " или "},
+	TimeAmbiguous:       {Other: "Уточните, пожалуйста, во сколько к вам приехать?"},
+	TimeInPast:          {Other: "Это время уже прошло. Уточните, во сколько к вам приехать?"},
+	DriverWaiting:       {Other: "Водитель Вас ожидает по адресу: %s"},
+	TripFinished:        {Other: "Ваша поездка завершена. Спасибо что воспользовались услугами нашей компании!"},
+	DriverFound:         {Other: "Водитель найден. Скоро он с Вами свяжется.\nВы можете написать водителю прямо в этот чат."},
+	ButtonCancelOrder:   {Other: "Отменить заказ"},
+	NoActiveOrder:       {Other: "У вас нет активного заказа"},
+	OrderInProgress:     {Other: "У вас уже есть активный заказ. Новый можно создать, когда он завершится или будет отменён."},
+	CannotCancel:        {Other: "Заказ уже нельзя отменить: он %s"},
+	OrderCancelled:      {Other: "Заказ отменён"},
+	OrderCancelledFee:   {Other: "Заказ отменён. Водитель уже был назначен, поэтому за отмену взимается штраф. Оплатить: %s"},
+	PaymentOrder:        {Other: "Оплата"},
+	PaymentLateFee:      {Other: "Штраф за позднюю отмену заказа"},
+	MessageFromDriver:   {Other: "Сообщение от водителя"},
+	OrderPaid:           {Other: "Заказ MOSCOW-%04d успешно оплачен"},
+	OrderReview:         {Other: "Проверьте заказ:\nОткуда: %s\nКуда: %s\nВремя: %s\nТелефон: %s"},
+	ButtonEditSource:    {Other: "Изменить адрес подачи"},
+	ButtonEditDest:      {Other: "Изменить адрес назначения"},
+	ButtonEditTime:      {Other: "Изменить время"},
+	ButtonEditPhone:     {Other: "Изменить телефон"},
+	ButtonConfirm:       {Other: "Подтвердить"},
+	PhoneInvalid:        {Other: "Не получилось распознать номер. Нажмите «Поделиться номером» или напишите его, например: +7 916 123-45-67."},
+	PhoneSaved:          {Other: "Номер для связи: %s"},
+	RateTrip:            {Other: "Оцените, пожалуйста, поездку:"},
+	AlreadyRated:        {Other: "Вы уже оценили эту поездку"},
+	TripNotFinished:     {Other: "Поездка ещё не завершена"},
+	AskComment:          {Other: "Спасибо за оценку! Если хотите, напишите комментарий к поездке."},
+	FeedbackThanks:      {Other: "Спасибо за отзыв!"},
+	DriverRating: {
+		One:   "Рейтинг водителя: %.1f ★ (%d оценка)",
+		Few:   "Рейтинг водителя: %.1f ★ (%d оценки)",
+		Many:  "Рейтинг водителя: %.1f ★ (%d оценок)",
+		Other: "Рейтинг водителя: %.1f ★ (%d оценки)",
+	},
+	NoOrders:          {Other: "У вас пока нет заказов."},
+	OrderHistory:      {Other: "Ваши заказы, страница %d:"},
+	OrderHistoryItem:  {Other: "№%d · %s · %s\n%s → %s"},
+	ButtonRepeatOrder: {Other: "Повторить №%d"},
+	ButtonNewerOrders: {Other: "← Новее"},
+	ButtonOlderOrders: {Other: "Старше →"},
+	ProfileCard:       {Other: "Профиль\nИмя: %s\nТелефон: %s"},
+	ProfileNotSet:     {Other: "не указано"},
+	ProfilePlaces:     {Other: "Сохранённые места:"},
+	ProfilePlace:      {Other: "• %s — %s"},
+	NoPlaces:          {Other: "Сохранённых мест пока нет. После поездки бот предложит сохранить её адреса."},
+	ButtonEditName:    {Other: "Изменить имя"},
+	ButtonDeletePlace: {Other: "Удалить «%s»"},
+	AskName:           {Other: "Как к вам обращаться?"},
+	ProfileSaved:      {Other: "Профиль сохранён"},
+	SavePlaces:        {Other: "Сохранить адреса поездки, чтобы выбирать их одной кнопкой?"},
+	ButtonSavePlace:   {Other: "Сохранить «%s»"},
+	AskPlaceName:      {Other: "Как назвать этот адрес? Выберите вариант или напишите своё название."},
+	PlaceHome:         {Other: "Дом"},
+	PlaceWork:         {Other: "Работа"},
+	PlaceSaved:        {Other: "Адрес сохранён как «%s»"},
+	PlaceNotSaved:     {Other: "Адрес не сохранён"},
+
+	DriverStart:         {Other: "Здравствуйте! Чтобы брать заказы, поделитесь номером телефона, который вы указали в заявке."},
+	ButtonShareContact:  {Other: "Поделиться номером"},
+	ShareOwnContact:     {Other: "Пожалуйста, поделитесь своим номером телефона."},
+	ApplicationNotFound: {Other: "Заявка с таким номером не найдена. Оставьте заявку на сайте."},
+	ApplicationLinked:   {Other: "Заявка с этим номером уже привязана к другому аккаунту Telegram. Обратитесь к администратору."},
+	ApplicationApproved: {Other: "Ваша заявка одобрена, теперь вы можете брать заказы."},
+	ApplicationRejected: {Other: "Ваша заявка отклонена."},
+	ApplicationPending:  {Other: "Спасибо! Мы сообщим, когда заявка будет одобрена."},
+	OnlyApprovedDrivers: {Other: "Брать заказы могут только одобренные водители. Напишите /start боту в личные сообщения."},
+	OrderAlreadyTaken:   {Other: "Заказ уже взят"},
+	ActionUnavailable:   {Other: "Действие недоступно: заказ %s"},
+	AskStartTrip:        {Other: "Когда клиент сядет в машину, начните поездку"},
+	ButtonStartTrip:     {Other: "Начать поездку"},
+	TripInProgress:      {Other: "Заказ в процессе"},
+	ButtonFinishTrip:    {Other: "Завершить заказ"},
+	DriverTripFinished:  {Other: "Заказ завершен."},
+	CommissionToPay:     {Other: "Комиссия сервиса за заказ: %s. Оплатить: %s"},
+	CommissionPaid:      {Other: "Комиссия за заказ MOSCOW-%04d оплачена. Спасибо!"},
+	PaymentCommission:   {Other: "Комиссия сервиса за заказ MOSCOW-%04d"},
+	ButtonArrived:       {Other: "Я на месте"},
+	DriverRelayHint:     {Other: "Телефон клиента скрыт, сообщения клиенту можно писать в этот чат."},
+	MessageFromCustomer: {Other: "Сообщение от клиента по заказу MOSCOW-%04d"},
+	DriverNoActiveOrder: {Other: "У вас нет активного заказа, сообщение не доставлено."},
+	RelayClosed:         {Other: "У этого заказа нет чата с клиентом, позвоните по телефону из заказа."},
+	CustomerCancelled:   {Other: "Клиент отменил заказ MOSCOW-%04d"},
+	VenueSource:         {Other: "Откуда"},
+	VenueDestination:    {Other: "Куда"},
+	StatsOnlyDrivers:    {Other: "Статистика доступна только водителям."},
+	StatsToday:          {Other: "Сегодня"},
+	StatsWeek:           {Other: "Эта неделя"},
+	StatsMonth:          {Other: "Этот месяц"},
+	StatsOrders: {
+		One:   "%d заказ выполнен",
+		Few:   "%d заказа выполнено",
+		Many:  "%d заказов выполнено",
+		Other: "%d заказа выполнено",
+	},
+	StatsDriving: {Other: "За рулём: %d ч %02d мин"},
+	StatsMoney:   {Other: "Выручка: %s\nКомиссия сервиса: %s\nНе оплачено комиссии: %s"},
+
+	RelayUnsupported: {Other: "Можно отправлять только текст, фото и геопозицию."},
+	ChooseLanguage:   {Other: "Выберите язык"},
+	LanguageChosen:   {Other: "Язык бота: русский"},
+	LanguageName:     {Other: "Русский"},
+	ButtonBack:       {Other: "⬅️ Назад"},
+	ButtonSkip:       {Other: "Пропустить"},
+	ChooseOption:     {Other: "Выберите один из вариантов на клавиатуре."},
+	AskText:          {Other: "Напишите ответ текстом."},
+	ButtonOutdated:   {Other: "Эта кнопка устарела, воспользуйтесь кнопками из нового сообщения."},
+
+	ButtonTakeOrder:    {Other: "Возьму заказ"},
+	OrderTakenBy:       {Other: "Уже взят (%d)"},
+	OrderCancelledMark: {Other: "Отменён клиентом"},
+	ApplicationNew:     {Other: "Новая заявка от водителя\nТелефон: %s\nИмя: %s\nСтаж: %s"},
+	ButtonApprove:      {Other: "Одобрить"},
+	ButtonReject:       {Other: "Отклонить"},
+	ResolutionApproved: {Other: "Одобрено"},
+	ResolutionRejected: {Other: "Отклонено"},
+	LowRating:          {Other: "Низкая оценка %d ★ по заказу MOSCOW-%04d, водитель %d"},
+	LowRatingComment:   {Other: "Комментарий к оценке заказа MOSCOW-%04d:\n%s"},
+	OrderDriverChat:    {Other: "ID: MOSCOW-%04d\nОткуда: %s\nКуда: %s\nВремя: %s"},
+	OrderPrivate:       {Other: "ID: MOSCOW-%04d\nОткуда: %s\nКуда: %s\nВремя: %s\nТелефон: %s"},
+	OrderPrice:         {Other: "Стоимость: %s"},
+	OrderPricePerHour:  {Other: "Стоимость: %s за первый час, далее %s/час"},
+
+	OrderStatusDraft:     {Other: "ещё не оформлен"},
+	OrderStatusAwaiting:  {Other: "ожидает водителя"},
+	OrderStatusAssigned:  {Other: "уже взят"},
+	OrderStatusArrived:   {Other: "ожидает клиента"},
+	OrderStatusProgress:  {Other: "уже в пути"},
+	OrderStatusFinished:  {Other: "уже завершён"},
+	OrderStatusCancelled: {Other: "отменён"},
+}
@@ -18,6 +18,14 @@ func PaymentID(paymentID uuid.UUID) zap.Field {
 	return zap.String("payment_id", paymentID.String())
 }
 
+func UpdateID(updateID int) zap.Field {
+	return zap.Int("update_id", updateID)
+}
+
+func TelegramID(telegramID int64) zap.Field {
+	return zap.Int64("telegram_id", telegramID)
+}
+
 func NewLogger() *zap.SugaredLogger {
 	logPath := os.Getenv("LOG_PATH")
 	if logPath == "" {
@@ -0,0 +1,322 @@
+package migrate
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// lockID is the key of the Postgres advisory lock held while migrating.
+const lockID = 7_305_148_226
+
+// createVersionTable is shared with goose, so databases migrated before the
+// runner was built into the binary keep their history.
+const createVersionTable = `
+CREATE TABLE IF NOT EXISTS goose_db_version
+(
+    id         serial PRIMARY KEY,
+    version_id bigint  NOT NULL,
+    is_applied boolean NOT NULL,
+    tstamp     timestamp DEFAULT now()
+);
+`
+
+const selectVersions = `
+SELECT version_id, is_applied, tstamp
+FROM goose_db_version
+ORDER BY id;
+`
+
+const insertVersion = `
+INSERT INTO goose_db_version (version_id, is_applied) VALUES ($1, $2);
+`
+
+const migrationTemplate = `-- +goose Up
+-- +goose StatementBegin
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+-- +goose StatementEnd
+`
+
+var ErrSchemaOutdated = errors.New("database schema is outdated")
+var ErrNoApplied = errors.New("no applied migrations")
+
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+type Status struct {
+	Migration Migration
+	AppliedAt *time.Time
+}
+
+type Migrator struct {
+	conn       *pgx.Conn
+	migrations []Migration
+}
+
+func New(conn *pgx.Conn, fsys fs.FS) (*Migrator, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{conn: conn, migrations: migrations}, nil
+}
+
+// Load reads goose-style SQL migrations from the root of fsys sorted by version.
+func Load(fsys fs.FS) ([]Migration, error) {
+	names, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, fmt.Errorf("fs.Glob: %w", err)
+	}
+	migrations := make([]Migration, 0, len(names))
+	for _, name := range names {
+		m, err := parse(fsys, name)
+		if err != nil {
+			return nil, fmt.Errorf("parse(%s): %w", name, err)
+		}
+		migrations = append(migrations, m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	for i := 1; i < len(migrations); i++ {
+		if migrations[i].Version == migrations[i-1].Version {
+			return nil, fmt.Errorf("duplicate migration version %d", migrations[i].Version)
+		}
+	}
+	return migrations, nil
+}
+
+// Up applies all pending migrations and returns them.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var applied []Migration
+	err := m.withLock(ctx, func() error {
+		statuses, err := m.status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, s := range statuses {
+			if s.AppliedAt != nil {
+				continue
+			}
+			if err = m.apply(ctx, s.Migration.Version, s.Migration.Up, true); err != nil {
+				return fmt.Errorf("%s: %w", s.Migration.Name, err)
+			}
+			applied = append(applied, s.Migration)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down rolls back the most recently applied migration and returns it.
+func (m *Migrator) Down(ctx context.Context) (*Migration, error) {
+	var rolledBack *Migration
+	err := m.withLock(ctx, func() error {
+		statuses, err := m.status(ctx)
+		if err != nil {
+			return err
+		}
+		for i := len(statuses) - 1; i >= 0; i-- {
+			if statuses[i].AppliedAt == nil {
+				continue
+			}
+			migration := statuses[i].Migration
+			if err = m.apply(ctx, migration.Version, migration.Down, false); err != nil {
+				return fmt.Errorf("%s: %w", migration.Name, err)
+			}
+			rolledBack = &migration
+			return nil
+		}
+		return ErrNoApplied
+	})
+	return rolledBack, err
+}
+
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	var statuses []Status
+	err := m.withLock(ctx, func() error {
+		var err error
+		statuses, err = m.status(ctx)
+		return err
+	})
+	return statuses, err
+}
+
+// Check returns ErrSchemaOutdated if any known migration is not applied.
+func (m *Migrator) Check(ctx context.Context) error {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+	var pending []string
+	for _, s := range statuses {
+		if s.AppliedAt == nil {
+			pending = append(pending, s.Migration.Name)
+		}
+	}
+	if len(pending) != 0 {
+		return fmt.Errorf("%w: pending %s", ErrSchemaOutdated, strings.Join(pending, ", "))
+	}
+	return nil
+}
+
+// Create writes an empty migration named after name into dir.
+func Create(dir string, name string, now time.Time) (string, error) {
+	name = strings.ToLower(strings.Join(strings.Fields(name), "_"))
+	if name == "" {
+		return "", errors.New("migration name is empty")
+	}
+	filename := filepath.Join(dir, fmt.Sprintf("%s_%s.sql", now.UTC().Format("20060102150405"), name))
+	f, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return "", fmt.Errorf("os.OpenFile: %w", err)
+	}
+	if _, err = f.WriteString(migrationTemplate); err != nil {
+		_ = f.Close()
+		return "", fmt.Errorf("f.WriteString: %w", err)
+	}
+	if err = f.Close(); err != nil {
+		return "", fmt.Errorf("f.Close: %w", err)
+	}
+	return filename, nil
+}
+
+func (m *Migrator) withLock(ctx context.Context, fn func() error) (err error) {
+	if _, err = m.conn.Exec(ctx, "SELECT pg_advisory_lock($1)", lockID); err != nil {
+		return fmt.Errorf("pg_advisory_lock: %w", err)
+	}
+	defer func() {
+		_, unlockErr := m.conn.Exec(context.Background(), "SELECT pg_advisory_unlock($1)", lockID)
+		if unlockErr != nil && err == nil {
+			err = fmt.Errorf("pg_advisory_unlock: %w", unlockErr)
+		}
+	}()
+	if _, err = m.conn.Exec(ctx, createVersionTable); err != nil {
+		return fmt.Errorf("conn.Exec(createVersionTable): %w", err)
+	}
+	return fn()
+}
+
+func (m *Migrator) status(ctx context.Context) ([]Status, error) {
+	rows, err := m.conn.Query(ctx, selectVersions)
+	if err != nil {
+		return nil, fmt.Errorf("conn.Query: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int64]*time.Time)
+	for rows.Next() {
+		var (
+			version   int64
+			isApplied bool
+			tstamp    *time.Time
+		)
+		if err = rows.Scan(&version, &isApplied, &tstamp); err != nil {
+			return nil, fmt.Errorf("rows.Scan: %w", err)
+		}
+		if !isApplied {
+			delete(applied, version)
+			continue
+		}
+		if tstamp == nil {
+			tstamp = &time.Time{}
+		}
+		applied[version] = tstamp
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows.Err: %w", err)
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		statuses = append(statuses, Status{Migration: migration, AppliedAt: applied[migration.Version]})
+	}
+	return statuses, nil
+}
+
+func (m *Migrator) apply(ctx context.Context, version int64, sql string, up bool) error {
+	tx, err := m.conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("conn.Begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if strings.TrimSpace(sql) != "" {
+		if _, err = tx.Exec(ctx, sql); err != nil {
+			return fmt.Errorf("tx.Exec: %w", err)
+		}
+	}
+	if _, err = tx.Exec(ctx, insertVersion, version, up); err != nil {
+		return fmt.Errorf("tx.Exec(insertVersion): %w", err)
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("tx.Commit: %w", err)
+	}
+	return nil
+}
+
+func parse(fsys fs.FS, name string) (Migration, error) {
+	prefix, _, ok := strings.Cut(path.Base(name), "_")
+	if !ok {
+		return Migration{}, errors.New("file name must look like <version>_<name>.sql")
+	}
+	version, err := strconv.ParseInt(prefix, 10, 64)
+	if err != nil {
+		return Migration{}, fmt.Errorf("strconv.ParseInt: %w", err)
+	}
+
+	f, err := fsys.Open(name)
+	if err != nil {
+		return Migration{}, fmt.Errorf("fsys.Open: %w", err)
+	}
+	defer f.Close()
+
+	var up, down strings.Builder
+	var section *strings.Builder
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch strings.TrimSpace(line) {
+		case "-- +goose Up":
+			section = &up
+			continue
+		case "-- +goose Down":
+			section = &down
+			continue
+		}
+		if strings.HasPrefix(strings.TrimSpace(line), "-- +goose") || section == nil {
+			continue
+		}
+		section.WriteString(line)
+		section.WriteByte('\n')
+	}
+	if err = scanner.Err(); err != nil {
+		return Migration{}, fmt.Errorf("scanner.Err: %w", err)
+	}
+	return Migration{
+		Version: version,
+		Name:    name,
+		Up:      up.String(),
+		Down:    down.String(),
+	}, nil
+}
@@ -0,0 +1,146 @@
+package migrate
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/andrey-berenda/perfect-driver/migrations"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    Migration
+		wantErr bool
+	}{
+		{
+			name: "statements",
+			file: "20230101120000_create_orders.sql",
+			content: `-- Orders of the customers.
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE orders
+(
+    id serial PRIMARY KEY
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE orders;
+-- +goose StatementEnd
+`,
+			want: Migration{
+				Version: 20230101120000,
+				Name:    "20230101120000_create_orders.sql",
+				Up:      "CREATE TABLE orders\n(\n    id serial PRIMARY KEY\n);\n\n",
+				Down:    "DROP TABLE orders;\n",
+			},
+		},
+		{
+			name:    "up only",
+			file:    "2_add_index.sql",
+			content: "  -- +goose Up  \nCREATE INDEX ON orders (id);",
+			want: Migration{
+				Version: 2,
+				Name:    "2_add_index.sql",
+				Up:      "CREATE INDEX ON orders (id);\n",
+			},
+		},
+		{
+			name:    "empty template",
+			file:    "3_nothing.sql",
+			content: migrationTemplate,
+			want:    Migration{Version: 3, Name: "3_nothing.sql", Up: "\n\n", Down: "\n"},
+		},
+		{name: "no name", file: "20230101120000.sql", wantErr: true},
+		{name: "no version", file: "create_orders.sql", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys := fstest.MapFS{tt.file: {Data: []byte(tt.content)}}
+			got, err := parse(fsys, tt.file)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parse(%s) = %+v, want an error", tt.file, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parse(%s): %v", tt.file, err)
+			}
+			if got != tt.want {
+				t.Errorf("parse(%s) = %+v, want %+v", tt.file, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	migration := []byte("-- +goose Up\nSELECT 1;\n-- +goose Down\nSELECT 2;\n")
+	fsys := fstest.MapFS{
+		"20230102000000_second.sql": {Data: migration},
+		"20230101000000_first.sql":  {Data: migration},
+		"README.md":                 {Data: []byte("not a migration")},
+	}
+	got, err := Load(fsys)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(got) != 2 || got[0].Version != 20230101000000 || got[1].Version != 20230102000000 {
+		t.Errorf("Load() = %+v, want the two migrations by version", got)
+	}
+
+	fsys["20230101000000_again.sql"] = &fstest.MapFile{Data: migration}
+	if _, err = Load(fsys); err == nil || !strings.Contains(err.Error(), "duplicate") {
+		t.Errorf("Load() error = %v, want a duplicate version", err)
+	}
+}
+
+func TestLoadRepositoryMigrations(t *testing.T) {
+	got, err := Load(migrations.FS)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(got) == 0 {
+		t.Fatal("Load() found no migrations")
+	}
+	for _, m := range got {
+		if strings.TrimSpace(m.Up) == "" {
+			t.Errorf("%s has no up statements", m.Name)
+		}
+	}
+}
+
+func TestCreate(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2023, time.May, 20, 9, 0, 0, 0, time.FixedZone("MSK", 3*60*60))
+
+	filename, err := Create(dir, "  Add   places table ", now)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if want := filepath.Join(dir, "20230520060000_add_places_table.sql"); filename != want {
+		t.Errorf("Create() = %s, want %s", filename, want)
+	}
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("os.ReadFile: %v", err)
+	}
+	if string(content) != migrationTemplate {
+		t.Errorf("created %q, want the template", content)
+	}
+
+	if _, err = Create(dir, "add places table", now); err == nil {
+		t.Error("Create() overwrote an existing migration")
+	}
+	if _, err = Create(dir, " ", now); err == nil {
+		t.Error("Create() accepted an empty name")
+	}
+}
@@ -0,0 +1,24 @@
+package models
+
+import "github.com/andrey-berenda/perfect-driver/internal/pkg/i18n"
+
+type DriverStatus string
+
+const (
+	DriverStatusPending  DriverStatus = "pending"
+	DriverStatusApproved DriverStatus = "approved"
+	DriverStatusBlocked  DriverStatus = "blocked"
+)
+
+type Driver struct {
+	ID         int
+	TelegramID *int64
+	Name       string
+	Phone      string
+	Experience string
+	Status     DriverStatus
+}
+
+func (d *Driver) ToAdminChat(p i18n.Printer) string {
+	return p.T(i18n.ApplicationNew, d.Phone, d.Name, d.Experience)
+}
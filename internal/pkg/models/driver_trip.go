@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// DriverTrip is a finished order as seen in the driver's earnings.
+type DriverTrip struct {
+	OrderID      int
+	Price        *Money
+	PricePerHour *Money
+	// StartedAt is nil for orders finished without being started.
+	StartedAt  *time.Time
+	FinishedAt time.Time
+	// CommissionPaid is set once the driver's commission payment for the
+	// order succeeded.
+	CommissionPaid bool
+}
+
+func (t *DriverTrip) Duration() time.Duration {
+	if t.StartedAt == nil || t.FinishedAt.Before(*t.StartedAt) {
+		return 0
+	}
+	return t.FinishedAt.Sub(*t.StartedAt)
+}
+
+// Fare is the price of the first hour plus the hourly price for every
+// started hour after it.
+func (t *DriverTrip) Fare() Money {
+	if t.Price == nil {
+		return 0
+	}
+	fare := *t.Price
+	if t.PricePerHour == nil {
+		return fare
+	}
+	extra := t.Duration() - time.Hour
+	for ; extra > 0; extra -= time.Hour {
+		fare += *t.PricePerHour
+	}
+	return fare
+}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDriverTripFare(t *testing.T) {
+	price := Rubles(1000)
+	perHour := Rubles(500)
+	finished := time.Date(2023, time.May, 15, 20, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name         string
+		price        *Money
+		pricePerHour *Money
+		// driving is nil for a trip that was never started.
+		driving *time.Duration
+		want    Money
+	}{
+		{name: "no price", driving: durationOf(2 * time.Hour), want: 0},
+		{name: "fixed price", price: &price, driving: durationOf(3 * time.Hour), want: price},
+		{name: "not started", price: &price, pricePerHour: &perHour, want: price},
+		{name: "first hour", price: &price, pricePerHour: &perHour, driving: durationOf(time.Hour), want: price},
+		{
+			name:         "a minute over",
+			price:        &price,
+			pricePerHour: &perHour,
+			driving:      durationOf(time.Hour + time.Minute),
+			want:         price + perHour,
+		},
+		{
+			name:         "second hour",
+			price:        &price,
+			pricePerHour: &perHour,
+			driving:      durationOf(2 * time.Hour),
+			want:         price + perHour,
+		},
+		{
+			name:         "started third hour",
+			price:        &price,
+			pricePerHour: &perHour,
+			driving:      durationOf(2*time.Hour + 30*time.Minute),
+			want:         price + 2*perHour,
+		},
+		{
+			name:         "finished before started",
+			price:        &price,
+			pricePerHour: &perHour,
+			driving:      durationOf(-time.Hour),
+			want:         price,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trip := &DriverTrip{Price: tt.price, PricePerHour: tt.pricePerHour, FinishedAt: finished}
+			if tt.driving != nil {
+				startedAt := finished.Add(-*tt.driving)
+				trip.StartedAt = &startedAt
+			}
+			if got := trip.Fare(); got != tt.want {
+				t.Errorf("Fare() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func durationOf(d time.Duration) *time.Duration {
+	return &d
+}
@@ -0,0 +1,22 @@
+package models
+
+import "fmt"
+
+// Money is an amount in kopecks.
+type Money int64
+
+func Rubles(rubles int64) Money {
+	return Money(rubles * 100)
+}
+
+// Value formats the amount the way YooKassa expects it, e.g. "500.00".
+func (m Money) Value() string {
+	return fmt.Sprintf("%d.%02d", m/100, m%100)
+}
+
+func (m Money) String() string {
+	if m%100 == 0 {
+		return fmt.Sprintf("%d ₽", m/100)
+	}
+	return fmt.Sprintf("%d,%02d ₽", m/100, m%100)
+}
@@ -2,31 +2,79 @@ package models
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
+
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/i18n"
+)
+
+type OrderStatus string
+
+const (
+	OrderStatusDraft          OrderStatus = "draft"
+	OrderStatusAwaitingDriver OrderStatus = "awaiting_driver"
+	OrderStatusAssigned       OrderStatus = "assigned"
+	OrderStatusDriverArrived  OrderStatus = "driver_arrived"
+	OrderStatusInProgress     OrderStatus = "in_progress"
+	OrderStatusFinished       OrderStatus = "finished"
+	OrderStatusCancelled      OrderStatus = "cancelled"
 )
 
 type Order struct {
-	ID          int
-	UserID      *uuid.UUID
-	TelegramID  int64
-	Source      *string
-	Destination *string
-	Phone       *string
-	Time        *string
+	ID         int
+	UserID     *uuid.UUID
+	TelegramID int64
+	Status     OrderStatus
+	// DriverTelegramID is the Telegram ID of the driver who took the order,
+	// not drivers.id.
+	DriverTelegramID *int64
+	Source           *string
+	Destination      *string
+	// SourcePoint and DestinationPoint are set when the customer sent a
+	// location or a venue instead of typing the address.
+	SourcePoint      *Point
+	DestinationPoint *Point
+	Phone            *string
+	Time             *string
+	ScheduledAt      *time.Time
+	TariffID         *int
+	// Price is the fare for the first hour, PricePerHour for every next one.
+	Price        *Money
+	PricePerHour *Money
+	// DriversChatMessageID is the post in the drivers chat announcing the order.
+	DriversChatMessageID *int
+	// FormStep is the step of the order form the customer is answering.
+	FormStep  *string
+	CreatedAt time.Time
+}
+
+func (o *Order) ToDriverChat(p i18n.Printer, loc *time.Location) string {
+	return p.T(i18n.OrderDriverChat, o.ID, *o.Source, *o.Destination, o.timeText(loc)) + o.priceText(p)
+}
+
+func (o *Order) ToPrivate(p i18n.Printer, loc *time.Location) string {
+	return p.T(i18n.OrderPrivate, o.ID, *o.Source, *o.Destination, o.timeText(loc), *o.Phone) + o.priceText(p)
+}
+
+func (o *Order) ToReview(p i18n.Printer, loc *time.Location) string {
+	return p.T(i18n.OrderReview, *o.Source, *o.Destination, o.timeText(loc), *o.Phone) + o.priceText(p)
 }
 
-func (o *Order) ToDriverChat() string {
-	return fmt.Sprintf(`ID: MOSCOW-%04d
-Откуда: %s
-Куда: %s
-Время: %s`, o.ID, *o.Source, *o.Destination, *o.Time)
+// timeText shows the resolved pickup time next to what the customer typed.
+func (o *Order) timeText(loc *time.Location) string {
+	if o.ScheduledAt == nil {
+		return *o.Time
+	}
+	return fmt.Sprintf("%s (%s)", o.ScheduledAt.In(loc).Format("02.01 15:04"), *o.Time)
 }
 
-func (o *Order) ToPrivate() string {
-	return fmt.Sprintf(`ID: MOSCOW-%04d
-Откуда: %s
-Куда: %s
-Время: %s
-Телефон: %s`, o.ID, *o.Source, *o.Destination, *o.Time, *o.Phone)
+func (o *Order) priceText(p i18n.Printer) string {
+	if o.Price == nil {
+		return ""
+	}
+	if o.PricePerHour == nil || *o.PricePerHour == 0 {
+		return "\n" + p.T(i18n.OrderPrice, *o.Price)
+	}
+	return "\n" + p.T(i18n.OrderPricePerHour, *o.Price, *o.PricePerHour)
 }
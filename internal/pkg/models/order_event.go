@@ -0,0 +1,31 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// OrderEventType names what happened to the order. Status changes use the
+// name of the status the order moved to.
+type OrderEventType string
+
+const (
+	OrderEventCreated        OrderEventType = "created"
+	OrderEventAwaitingDriver                = OrderEventType(OrderStatusAwaitingDriver)
+	OrderEventAssigned                      = OrderEventType(OrderStatusAssigned)
+	OrderEventDriverArrived                 = OrderEventType(OrderStatusDriverArrived)
+	OrderEventInProgress                    = OrderEventType(OrderStatusInProgress)
+	OrderEventFinished                      = OrderEventType(OrderStatusFinished)
+	OrderEventCancelled                     = OrderEventType(OrderStatusCancelled)
+	// OrderEventMessage is a message relayed between the customer and the driver.
+	OrderEventMessage OrderEventType = "message"
+)
+
+type OrderEvent struct {
+	ID        int64
+	OrderID   int
+	Type      OrderEventType
+	ActorID   int64
+	Payload   json.RawMessage
+	CreatedAt time.Time
+}
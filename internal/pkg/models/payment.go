@@ -13,9 +13,20 @@ const (
 	PaymentStatusCanceled          PaymentStatus = "canceled"
 )
 
+// PaymentPurpose tells who pays and for what: customers pay for orders and
+// late cancellations, drivers pay the service commission.
+type PaymentPurpose string
+
+const (
+	PaymentPurposeOrder      PaymentPurpose = "order"
+	PaymentPurposeLateFee    PaymentPurpose = "late_fee"
+	PaymentPurposeCommission PaymentPurpose = "commission"
+)
+
 type Payment struct {
 	ID              uuid.UUID
 	OrderID         int
 	ConfirmationURL string
 	Status          PaymentStatus
+	Purpose         PaymentPurpose
 }
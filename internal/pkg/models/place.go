@@ -0,0 +1,13 @@
+package models
+
+import "github.com/google/uuid"
+
+// Place is an address the user saved under a name, e.g. "Home".
+type Place struct {
+	ID     int
+	UserID uuid.UUID
+	// Name is empty while the user is naming a new place.
+	Name    string
+	Address string
+	Point   *Point
+}
@@ -0,0 +1,12 @@
+package models
+
+import "fmt"
+
+type Point struct {
+	Latitude  float64
+	Longitude float64
+}
+
+func (p Point) String() string {
+	return fmt.Sprintf("%.5f, %.5f", p.Latitude, p.Longitude)
+}
@@ -0,0 +1,22 @@
+package models
+
+const (
+	MinScore = 1
+	MaxScore = 5
+)
+
+// Rating is the score a customer gave the driver of a finished order.
+type Rating struct {
+	OrderID int
+	// DriverTelegramID is the Telegram ID of the driver, not drivers.id.
+	DriverTelegramID int64
+	Score            int
+	// Comment is nil until the customer writes a comment or skips it, and
+	// empty if they skipped it.
+	Comment *string
+}
+
+type DriverRating struct {
+	Average float64
+	Count   int
+}
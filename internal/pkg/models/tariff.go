@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+type Tariff struct {
+	ID             int
+	Name           string
+	BaseFare       Money
+	PerHour        Money
+	NightSurcharge Money
+	NightStart     int
+	NightEnd       int
+}
+
+// IsNight reports whether the hour of t falls into the tariff's night window.
+// The window may wrap around midnight, e.g. 22 to 6.
+func (t *Tariff) IsNight(at time.Time) bool {
+	hour := at.Hour()
+	if t.NightStart <= t.NightEnd {
+		return hour >= t.NightStart && hour < t.NightEnd
+	}
+	return hour >= t.NightStart || hour < t.NightEnd
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTariffIsNight(t *testing.T) {
+	tests := []struct {
+		name       string
+		start, end int
+		// night lists the hours inside the window.
+		night []int
+	}{
+		{name: "across midnight", start: 22, end: 6, night: []int{22, 23, 0, 1, 2, 3, 4, 5}},
+		{name: "from midnight", start: 0, end: 5, night: []int{0, 1, 2, 3, 4}},
+		{name: "within a day", start: 1, end: 4, night: []int{1, 2, 3}},
+		{name: "empty", start: 3, end: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tariff := &Tariff{NightStart: tt.start, NightEnd: tt.end}
+			night := make(map[int]bool, len(tt.night))
+			for _, h := range tt.night {
+				night[h] = true
+			}
+			for hour := 0; hour < 24; hour++ {
+				for _, minute := range []int{0, 59} {
+					at := time.Date(2023, time.May, 15, hour, minute, 0, 0, time.UTC)
+					if got := tariff.IsNight(at); got != night[hour] {
+						t.Errorf("IsNight(%s) = %t, want %t", at.Format("15:04"), got, night[hour])
+					}
+				}
+			}
+		})
+	}
+}
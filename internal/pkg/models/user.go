@@ -7,4 +7,13 @@ type UserStep string
 type User struct {
 	ID         uuid.UUID
 	TelegramID int64
+	// Language is empty until the user is first seen with a language code
+	// or picks one with /language.
+	Language string
+	// Phone is the E.164 number the user gave in their last order or in
+	// the profile. It is filled in new orders.
+	Phone string
+	Name  string
+	// FormStep is the step of the profile form the user is answering.
+	FormStep string
 }
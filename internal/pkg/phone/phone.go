@@ -0,0 +1,45 @@
+package phone
+
+import (
+	"errors"
+	"strings"
+)
+
+var ErrInvalid = errors.New("phone number is invalid")
+
+// Normalize converts a phone number to E.164. Numbers without a country
+// code are taken as Russian ones, e.g. "8 (916) 123-45-67" and
+// "916 123 45 67" both become "+79161234567".
+func Normalize(number string) (string, error) {
+	number = strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\u00a0', '(', ')', '-', '.':
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(number))
+
+	international := strings.HasPrefix(number, "+")
+	digits := strings.TrimPrefix(number, "+")
+	if digits == "" || strings.Trim(digits, "0123456789") != "" {
+		return "", ErrInvalid
+	}
+
+	switch {
+	case international && strings.HasPrefix(digits, "7"):
+		if len(digits) != 11 {
+			return "", ErrInvalid
+		}
+	case international:
+		if len(digits) < 8 || len(digits) > 15 || digits[0] == '0' {
+			return "", ErrInvalid
+		}
+	case len(digits) == 11 && (digits[0] == '8' || digits[0] == '7'):
+		digits = "7" + digits[1:]
+	case len(digits) == 10 && digits[0] == '9':
+		digits = "7" + digits
+	default:
+		return "", ErrInvalid
+	}
+	return "+" + digits, nil
+}
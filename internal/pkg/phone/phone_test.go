@@ -0,0 +1,45 @@
+package phone
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		number  string
+		want    string
+		wantErr error
+	}{
+		{number: "+79161234567", want: "+79161234567"},
+		{number: "+7 (916) 123-45-67", want: "+79161234567"},
+		{number: "8 (916) 123-45-67", want: "+79161234567"},
+		{number: "79161234567", want: "+79161234567"},
+		{number: "916 123 45 67", want: "+79161234567"},
+		{number: " 8.916.123.45.67 ", want: "+79161234567"},
+		{number: "8 916 1234567", want: "+79161234567"},
+		{number: "+375 29 123-45-67", want: "+375291234567"},
+		{number: "+44 20 7946 0958", want: "+442079460958"},
+		{number: "", wantErr: ErrInvalid},
+		{number: "+", wantErr: ErrInvalid},
+		{number: "call me", wantErr: ErrInvalid},
+		{number: "+7916123456", wantErr: ErrInvalid},
+		{number: "+0123456789", wantErr: ErrInvalid},
+		{number: "+1234567", wantErr: ErrInvalid},
+		{number: "+1234567890123456", wantErr: ErrInvalid},
+		{number: "1234567890", wantErr: ErrInvalid},
+		{number: "99161234567", wantErr: ErrInvalid},
+		{number: "8 916 123-45-6x", wantErr: ErrInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.number, func(t *testing.T) {
+			got, err := Normalize(tt.number)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Normalize(%q) error = %v, want %v", tt.number, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.number, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,235 @@
+package pickuptime
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrUnrecognized = errors.New("pickup time is not recognized")
+var ErrInPast = errors.New("pickup time is in the past")
+
+// AmbiguousError is returned when the input fits several moments, e.g. "в 9"
+// said in the afternoon may mean this evening or tomorrow morning.
+type AmbiguousError struct {
+	Options []time.Time
+}
+
+func (e *AmbiguousError) Error() string {
+	return fmt.Sprintf("pickup time is ambiguous: %d options", len(e.Options))
+}
+
+var nowWords = map[string]bool{
+	"сейчас":            true,
+	"прямо сейчас":      true,
+	"срочно":            true,
+	"сразу":             true,
+	"как можно скорее":  true,
+	"как можно быстрее": true,
+	"now":               true,
+	"asap":              true,
+}
+
+var (
+	relativeRe = regexp.MustCompile(`^через\s+(\d+(?:[.,]\d+)?)?\s*(минут[уы]?|мин\.?|м|час(?:а|ов)?|ч\.?|полчаса)$`)
+	dateRe     = regexp.MustCompile(`^(\d{1,2})\.(\d{1,2})(?:\.(\d{2}|\d{4}))?(?:\s+(?:в\s+)?(.+))?$`)
+	clockRe    = regexp.MustCompile(`^(?:в\s+)?(\d{1,2})(?:[:.\s](\d{2}))?(?:\s*(?:ч\.?|час(?:а|ов)?))?(?:\s+(утра|дня|вечера|ночи))?$`)
+)
+
+// Parse resolves a pickup time typed by a customer, such as "сейчас",
+// "через 30 минут", "в 23:15", "завтра в 9" or "15.05 18:00", relative to
+// now in loc.
+func Parse(input string, now time.Time, loc *time.Location) (time.Time, error) {
+	now = now.In(loc)
+	text := normalize(input)
+	if text == "" {
+		return time.Time{}, ErrUnrecognized
+	}
+
+	if nowWords[text] {
+		return now, nil
+	}
+
+	if m := relativeRe.FindStringSubmatch(text); m != nil {
+		return parseRelative(m[1], m[2], now)
+	}
+
+	for _, day := range []struct {
+		prefix string
+		offset int
+	}{
+		{"сегодня", 0},
+		{"послезавтра", 2},
+		{"завтра", 1},
+	} {
+		if !strings.HasPrefix(text, day.prefix) {
+			continue
+		}
+		rest := strings.TrimSpace(strings.TrimPrefix(text, day.prefix))
+		date := now.AddDate(0, 0, day.offset)
+		if rest == "" {
+			return time.Time{}, &AmbiguousError{}
+		}
+		return parseClockOn(rest, date, now, loc)
+	}
+
+	if m := dateRe.FindStringSubmatch(text); m != nil {
+		t, err := parseDate(m, now, loc)
+		// "23.15" is a time of day rather than a date.
+		if !errors.Is(err, ErrUnrecognized) || m[3] != "" || m[4] != "" {
+			return t, err
+		}
+	}
+
+	return parseClock(text, now, loc)
+}
+
+func normalize(input string) string {
+	text := strings.ToLower(strings.TrimSpace(input))
+	text = strings.ReplaceAll(text, "ё", "е")
+	text = strings.Trim(text, ".!?")
+	return strings.Join(strings.Fields(text), " ")
+}
+
+func parseRelative(amount string, unit string, now time.Time) (time.Time, error) {
+	if unit == "полчаса" {
+		if amount != "" {
+			return time.Time{}, ErrUnrecognized
+		}
+		return now.Add(30 * time.Minute), nil
+	}
+
+	value := 1.0
+	if amount != "" {
+		var err error
+		value, err = strconv.ParseFloat(strings.Replace(amount, ",", ".", 1), 64)
+		if err != nil {
+			return time.Time{}, ErrUnrecognized
+		}
+	}
+
+	d := time.Minute
+	if strings.HasPrefix(unit, "ч") {
+		d = time.Hour
+	} else if amount == "" {
+		return time.Time{}, ErrUnrecognized
+	}
+	return now.Add(time.Duration(value * float64(d))).Truncate(time.Minute), nil
+}
+
+func parseDate(m []string, now time.Time, loc *time.Location) (time.Time, error) {
+	day, _ := strconv.Atoi(m[1])
+	month, _ := strconv.Atoi(m[2])
+	year := now.Year()
+	if m[3] != "" {
+		year, _ = strconv.Atoi(m[3])
+		if year < 100 {
+			year += 2000
+		}
+	}
+	date := time.Date(year, time.Month(month), day, 0, 0, 0, 0, loc)
+	// A date without a year that has passed is next year's, like a time of
+	// day that has passed is tomorrow's.
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	if m[3] == "" && date.Before(today) {
+		date = time.Date(year+1, time.Month(month), day, 0, 0, 0, 0, loc)
+	}
+	if date.Day() != day || int(date.Month()) != month {
+		return time.Time{}, ErrUnrecognized
+	}
+	if m[4] == "" {
+		return time.Time{}, &AmbiguousError{}
+	}
+	return parseClockOn(m[4], date, now, loc)
+}
+
+// parseClock resolves a time of day without a date to its next occurrence.
+func parseClock(text string, now time.Time, loc *time.Location) (time.Time, error) {
+	hour, minute, partOfDay, err := splitClock(text)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	next := func(h int) time.Time {
+		t := time.Date(now.Year(), now.Month(), now.Day(), h, minute, 0, 0, loc)
+		if t.Before(now.Truncate(time.Minute)) {
+			t = t.AddDate(0, 0, 1)
+		}
+		return t
+	}
+
+	if partOfDay != "" {
+		return next(applyPartOfDay(hour, partOfDay)), nil
+	}
+
+	morning := next(hour)
+	if hour == 0 || hour >= 12 {
+		return morning, nil
+	}
+	// "в 9" said at 15:00 is either 21:00 today or 9:00 tomorrow.
+	evening := next(hour + 12)
+	if morning.Day() != now.Day() && evening.Day() == now.Day() {
+		return time.Time{}, &AmbiguousError{Options: []time.Time{evening, morning}}
+	}
+	return morning, nil
+}
+
+// parseClockOn resolves a time of day on the given date.
+func parseClockOn(text string, date time.Time, now time.Time, loc *time.Location) (time.Time, error) {
+	text = strings.TrimSpace(strings.TrimPrefix(text, "в "))
+	hour, minute, partOfDay, err := splitClock(text)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if partOfDay != "" {
+		hour = applyPartOfDay(hour, partOfDay)
+	}
+	t := time.Date(date.Year(), date.Month(), date.Day(), hour, minute, 0, 0, loc)
+	if t.Before(now.Truncate(time.Minute)) {
+		return time.Time{}, ErrInPast
+	}
+	return t, nil
+}
+
+func splitClock(text string) (int, int, string, error) {
+	m := clockRe.FindStringSubmatch(text)
+	if m == nil {
+		return 0, 0, "", ErrUnrecognized
+	}
+	hour, _ := strconv.Atoi(m[1])
+	minute := 0
+	if m[2] != "" {
+		minute, _ = strconv.Atoi(m[2])
+	}
+	if hour > 23 || minute > 59 {
+		return 0, 0, "", ErrUnrecognized
+	}
+	if m[3] != "" && hour > 12 {
+		return 0, 0, "", ErrUnrecognized
+	}
+	return hour, minute, m[3], nil
+}
+
+func applyPartOfDay(hour int, partOfDay string) int {
+	switch partOfDay {
+	case "утра":
+		if hour == 12 {
+			return 0
+		}
+	case "дня", "вечера":
+		if hour < 12 {
+			return hour + 12
+		}
+	case "ночи":
+		if hour == 12 {
+			return 0
+		}
+		if hour >= 9 {
+			return hour + 12
+		}
+	}
+	return hour
+}
@@ -0,0 +1,80 @@
+package pickuptime
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		t.Fatalf("time.LoadLocation: %v", err)
+	}
+	at := func(month time.Month, day, hour, minute int) time.Time {
+		year := 2023
+		if month < time.October {
+			year = 2024
+		}
+		return time.Date(year, month, day, hour, minute, 0, 0, loc)
+	}
+	// Monday afternoon.
+	now := at(time.October, 16, 15, 0)
+
+	tests := []struct {
+		input   string
+		want    time.Time
+		options []time.Time
+		wantErr error
+	}{
+		{input: "Сейчас!", want: now},
+		{input: "через 30 минут", want: at(time.October, 16, 15, 30)},
+		{input: "через полчаса", want: at(time.October, 16, 15, 30)},
+		{input: "через 1,5 часа", want: at(time.October, 16, 16, 30)},
+		{input: "в 23:15", want: at(time.October, 16, 23, 15)},
+		{input: "23.15", want: at(time.October, 16, 23, 15)},
+		{input: "в 9 утра", want: at(time.October, 17, 9, 0)},
+		{input: "в 9", options: []time.Time{at(time.October, 16, 21, 0), at(time.October, 17, 9, 0)}},
+		{input: "завтра в 9", want: at(time.October, 17, 9, 0)},
+		{input: "послезавтра в 7 вечера", want: at(time.October, 18, 19, 0)},
+		{input: "20.10 18:00", want: at(time.October, 20, 18, 0)},
+		{input: "15.05 в 18:00", want: at(time.May, 15, 18, 0)},
+		{input: "завтра", options: []time.Time{}},
+		{input: "20.10", options: []time.Time{}},
+		{input: "сегодня в 10", wantErr: ErrInPast},
+		{input: "16.10 10:00", wantErr: ErrInPast},
+		{input: "15.05.2023 18:00", wantErr: ErrInPast},
+		{input: "31.02 10:00", wantErr: ErrUnrecognized},
+		{input: "в 25", wantErr: ErrUnrecognized},
+		{input: "когда-нибудь", wantErr: ErrUnrecognized},
+		{input: "", wantErr: ErrUnrecognized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := Parse(tt.input, now, loc)
+			var ambiguous *AmbiguousError
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Parse(%q) error = %v, want %v", tt.input, err, tt.wantErr)
+				}
+			case tt.options != nil:
+				if !errors.As(err, &ambiguous) {
+					t.Fatalf("Parse(%q) error = %v, want ambiguous", tt.input, err)
+				}
+				if len(ambiguous.Options) != len(tt.options) {
+					t.Fatalf("Parse(%q) options = %v, want %v", tt.input, ambiguous.Options, tt.options)
+				}
+				for i := range tt.options {
+					if !ambiguous.Options[i].Equal(tt.options[i]) {
+						t.Errorf("Parse(%q) option %d = %v, want %v", tt.input, i, ambiguous.Options[i], tt.options[i])
+					}
+				}
+			case err != nil:
+				t.Errorf("Parse(%q) error = %v", tt.input, err)
+			case !got.Equal(tt.want):
+				t.Errorf("Parse(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package pricing
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/models"
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/storage"
+)
+
+type Pricer struct {
+	tariffs  storage.TariffRepository
+	location *time.Location
+}
+
+func New(tariffs storage.TariffRepository, location *time.Location) *Pricer {
+	return &Pricer{tariffs: tariffs, location: location}
+}
+
+// Quote prices the order with the active tariff. The base fare covers the
+// first hour and gets the night surcharge if the pickup is at night in the
+// city timezone. Orders without a parsed pickup time are priced as of now.
+func (p *Pricer) Quote(ctx context.Context, order *models.Order, now time.Time) (storage.OrderPatch, error) {
+	tariff, err := p.tariffs.TariffGetActive(ctx)
+	if err != nil {
+		return storage.OrderPatch{}, fmt.Errorf("tariffs.TariffGetActive: %w", err)
+	}
+
+	pickupAt := now
+	if order.ScheduledAt != nil {
+		pickupAt = *order.ScheduledAt
+	}
+	price := tariff.BaseFare
+	if tariff.IsNight(pickupAt.In(p.location)) {
+		price += tariff.NightSurcharge
+	}
+	return storage.OrderPatch{
+		TariffID:     &tariff.ID,
+		Price:        &price,
+		PricePerHour: &tariff.PerHour,
+	}, nil
+}
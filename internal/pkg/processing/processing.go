@@ -3,7 +3,9 @@ package processing
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -11,21 +13,33 @@ import (
 
 	"github.com/google/uuid"
 
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/config"
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/i18n"
 	"github.com/andrey-berenda/perfect-driver/internal/pkg/models"
 	"github.com/andrey-berenda/perfect-driver/internal/pkg/storage"
 )
 
+// Processor creates payments with descriptions printed by p, in the language
+// of the customer who pays.
 type Processor interface {
-	CreatePayment(ctx context.Context, orderID int) (string, error)
+	CreatePayment(ctx context.Context, p i18n.Printer, order models.Order) (string, error)
 	CheckOrder(ctx context.Context, payment models.Payment) (*models.Payment, error)
+	// ChargeCancellation is called after the customer cancels the order. It
+	// returns the confirmation URL of the late-cancellation fee, or "" if the
+	// cancellation is free.
+	ChargeCancellation(ctx context.Context, p i18n.Printer, order models.Order) (string, error)
+	// ChargeCommission bills the driver the service commission of a
+	// finished order and returns the confirmation URL.
+	ChargeCommission(ctx context.Context, p i18n.Printer, orderID int, commission models.Money) (string, error)
 }
 
 type youMoneyProcessor struct {
-	store            *storage.Store
-	httpClient       *http.Client
-	authorization    string
-	createOrderURL   string
-	buildGetOrderURL func(orderID uuid.UUID) string
+	store          storage.PaymentRepository
+	httpClient     *http.Client
+	authorization  string
+	createOrderURL string
+	returnURL      string
+	lateFee        models.Money
 }
 
 type Amount struct {
@@ -66,39 +80,83 @@ type OrderResponse struct {
 	Description   string               `json:"description"`
 }
 
-func newRequest() CreateOrderRequest {
+var ErrNoPrice = errors.New("order has no price")
+
+func newRequest(returnURL string, amount models.Money, description string) CreateOrderRequest {
 	return CreateOrderRequest{
 		Amount: Amount{
 			Currency: "RUB",
-			Value:    "500",
+			Value:    amount.Value(),
 		},
 		Capture: true,
 		Confirmation: Confirmation{
 			Type:      "redirect",
-			ReturnURL: "https://t.me/PerfectDriverBot",
+			ReturnURL: returnURL,
 		},
-		Description: "Оплата",
+		Description: description,
 	}
 }
 
 func New(
-	store *storage.Store,
+	store storage.PaymentRepository,
 	httpClient *http.Client,
-	authorization string,
-	createOrderURL string,
-	buildGetOrderURL func(orderID uuid.UUID) string,
+	cfg config.YooKassa,
+	cancellation config.Cancellation,
 ) Processor {
 	return &youMoneyProcessor{
-		store:            store,
-		httpClient:       httpClient,
-		authorization:    authorization,
-		createOrderURL:   createOrderURL,
-		buildGetOrderURL: buildGetOrderURL,
+		store:      store,
+		httpClient: httpClient,
+		authorization: base64.StdEncoding.EncodeToString(
+			[]byte(fmt.Sprintf("%d:%s", cfg.ShopID, cfg.SecretKey)),
+		),
+		createOrderURL: cfg.PaymentsURL,
+		returnURL:      cfg.ReturnURL,
+		lateFee:        models.Rubles(cancellation.LateFee),
+	}
+}
+
+func (p *youMoneyProcessor) getOrderURL(paymentID uuid.UUID) string {
+	return fmt.Sprintf("%s/%s", p.createOrderURL, paymentID.String())
+}
+
+func (p *youMoneyProcessor) CreatePayment(ctx context.Context, printer i18n.Printer, order models.Order) (string, error) {
+	if order.Price == nil {
+		return "", ErrNoPrice
 	}
+	return p.createPayment(ctx, order.ID, models.PaymentPurposeOrder, *order.Price, printer.T(i18n.PaymentOrder))
+}
+
+// ChargeCancellation charges the late fee only if a driver had already taken
+// the order.
+func (p *youMoneyProcessor) ChargeCancellation(
+	ctx context.Context,
+	printer i18n.Printer,
+	order models.Order,
+) (string, error) {
+	if p.lateFee == 0 || order.DriverTelegramID == nil {
+		return "", nil
+	}
+	return p.createPayment(ctx, order.ID, models.PaymentPurposeLateFee, p.lateFee, printer.T(i18n.PaymentLateFee))
+}
+
+func (p *youMoneyProcessor) ChargeCommission(
+	ctx context.Context,
+	printer i18n.Printer,
+	orderID int,
+	commission models.Money,
+) (string, error) {
+	description := printer.T(i18n.PaymentCommission, orderID)
+	return p.createPayment(ctx, orderID, models.PaymentPurposeCommission, commission, description)
 }
 
-func (p *youMoneyProcessor) CreatePayment(ctx context.Context, orderID int) (string, error) {
-	body, err := json.MarshalIndent(newRequest(), "", "  ")
+func (p *youMoneyProcessor) createPayment(
+	ctx context.Context,
+	orderID int,
+	purpose models.PaymentPurpose,
+	amount models.Money,
+	description string,
+) (string, error) {
+	body, err := json.MarshalIndent(newRequest(p.returnURL, amount, description), "", "  ")
 	if err != nil {
 		return "", fmt.Errorf("json.Marshal: %w", err)
 	}
@@ -143,6 +201,7 @@ func (p *youMoneyProcessor) CreatePayment(ctx context.Context, orderID int) (str
 		OrderID:         orderID,
 		Status:          models.PaymentStatusPending,
 		ConfirmationURL: resp.Confirmation.ConfirmationURL,
+		Purpose:         purpose,
 	}
 
 	if err = p.store.PaymentCreate(ctx, payment); err != nil {
@@ -156,7 +215,7 @@ func (p *youMoneyProcessor) CheckOrder(ctx context.Context, payment models.Payme
 	httpRequest, err := http.NewRequestWithContext(
 		ctx,
 		http.MethodGet,
-		p.buildGetOrderURL(payment.ID),
+		p.getOrderURL(payment.ID),
 		nil,
 	)
 	if err != nil {
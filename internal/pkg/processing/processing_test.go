@@ -0,0 +1,239 @@
+package processing_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/config"
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/i18n"
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/models"
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/processing"
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/storage"
+)
+
+const confirmationURL = "https://yookassa.example/confirm"
+
+// yooKassa is a fake payments API. It creates payments with the amount and
+// the description it was sent and reports the status of the known ones.
+type yooKassa struct {
+	amount      string
+	description string
+	statuses    map[uuid.UUID]models.PaymentStatus
+}
+
+func (y *yooKassa) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPost {
+		var request processing.CreateOrderRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		y.amount = request.Amount.Value
+		y.description = request.Description
+		_ = json.NewEncoder(w).Encode(processing.OrderResponse{
+			ID:           uuid.New(),
+			Status:       models.PaymentStatusPending,
+			Confirmation: processing.Confirmation{ConfirmationURL: confirmationURL},
+		})
+		return
+	}
+	id, err := uuid.Parse(r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	status, ok := y.statuses[id]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(processing.OrderResponse{ID: id, Status: status})
+}
+
+func newProcessor(t *testing.T, store storage.PaymentRepository, lateFee int64) (processing.Processor, *yooKassa) {
+	t.Helper()
+	api := &yooKassa{statuses: make(map[uuid.UUID]models.PaymentStatus)}
+	srv := httptest.NewServer(api)
+	t.Cleanup(srv.Close)
+	p := processing.New(
+		store,
+		srv.Client(),
+		config.YooKassa{ShopID: 1, SecretKey: "secret", PaymentsURL: srv.URL + "/payments"},
+		config.Cancellation{LateFee: lateFee},
+	)
+	return p, api
+}
+
+func TestCreatePayment(t *testing.T) {
+	price := models.Rubles(1500)
+	tests := []struct {
+		name        string
+		order       models.Order
+		lang        i18n.Lang
+		description string
+		wantErr     error
+	}{
+		{
+			name:    "no price",
+			order:   models.Order{ID: 1},
+			lang:    i18n.Russian,
+			wantErr: processing.ErrNoPrice,
+		},
+		{
+			name:        "russian",
+			order:       models.Order{ID: 2, Price: &price},
+			lang:        i18n.Russian,
+			description: "Оплата",
+		},
+		{
+			name:        "english",
+			order:       models.Order{ID: 3, Price: &price},
+			lang:        i18n.English,
+			description: "Payment",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := storage.NewMemoryStore()
+			p, api := newProcessor(t, store, 0)
+
+			url, err := p.CreatePayment(ctx, i18n.New(tt.lang), tt.order)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("CreatePayment error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreatePayment: %v", err)
+			}
+			if url != confirmationURL {
+				t.Errorf("url = %q, want %q", url, confirmationURL)
+			}
+			if api.description != tt.description {
+				t.Errorf("description = %q, want %q", api.description, tt.description)
+			}
+			payments, err := store.PaymentsForCheck(ctx)
+			if err != nil {
+				t.Fatalf("store.PaymentsForCheck: %v", err)
+			}
+			if len(payments) != 1 || payments[0].OrderID != tt.order.ID {
+				t.Errorf("pending payments = %v, want one of order %d", payments, tt.order.ID)
+			}
+		})
+	}
+}
+
+func TestChargeCancellation(t *testing.T) {
+	driverTelegramID := int64(7)
+	tests := []struct {
+		name    string
+		lateFee int64
+		order   models.Order
+		charged bool
+	}{
+		{name: "no fee configured", order: models.Order{ID: 1, DriverTelegramID: &driverTelegramID}},
+		{name: "no driver yet", lateFee: 300, order: models.Order{ID: 2}},
+		{name: "driver assigned", lateFee: 300, order: models.Order{ID: 3, DriverTelegramID: &driverTelegramID}, charged: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := storage.NewMemoryStore()
+			p, api := newProcessor(t, store, tt.lateFee)
+
+			url, err := p.ChargeCancellation(context.Background(), i18n.New(i18n.Russian), tt.order)
+			if err != nil {
+				t.Fatalf("ChargeCancellation: %v", err)
+			}
+			if !tt.charged {
+				if url != "" {
+					t.Errorf("url = %q, want none", url)
+				}
+				return
+			}
+			if url != confirmationURL {
+				t.Errorf("url = %q, want %q", url, confirmationURL)
+			}
+			if want := "Штраф за позднюю отмену заказа"; api.description != want {
+				t.Errorf("description = %q, want %q", api.description, want)
+			}
+		})
+	}
+}
+
+func TestChargeCommission(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemoryStore()
+	p, api := newProcessor(t, store, 0)
+
+	url, err := p.ChargeCommission(ctx, i18n.New(i18n.English), 12, models.Rubles(150)+50)
+	if err != nil {
+		t.Fatalf("ChargeCommission: %v", err)
+	}
+	if url != confirmationURL {
+		t.Errorf("url = %q, want %q", url, confirmationURL)
+	}
+	if want := (models.Rubles(150) + 50).Value(); api.amount != want {
+		t.Errorf("amount = %q, want %q", api.amount, want)
+	}
+	if want := "Service commission for order MOSCOW-0012"; api.description != want {
+		t.Errorf("description = %q, want %q", api.description, want)
+	}
+	payments, err := store.PaymentsForCheck(ctx)
+	if err != nil {
+		t.Fatalf("store.PaymentsForCheck: %v", err)
+	}
+	if len(payments) != 1 || payments[0].OrderID != 12 || payments[0].Purpose != models.PaymentPurposeCommission {
+		t.Errorf("pending payments = %v, want a commission of order 12", payments)
+	}
+}
+
+func TestCheckOrder(t *testing.T) {
+	tests := []struct {
+		name   string
+		known  bool
+		status models.PaymentStatus
+		want   models.PaymentStatus
+	}{
+		{name: "pending", known: true, status: models.PaymentStatusPending, want: models.PaymentStatusPending},
+		{name: "succeeded", known: true, status: models.PaymentStatusSucceeded, want: models.PaymentStatusSucceeded},
+		{name: "unknown payment is canceled", want: models.PaymentStatusCanceled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := storage.NewMemoryStore()
+			p, api := newProcessor(t, store, 0)
+			payment := models.Payment{ID: uuid.New(), OrderID: 1, Status: models.PaymentStatusPending}
+			if err := store.PaymentCreate(ctx, payment); err != nil {
+				t.Fatalf("store.PaymentCreate: %v", err)
+			}
+			if tt.known {
+				api.statuses[payment.ID] = tt.status
+			}
+
+			checked, err := p.CheckOrder(ctx, payment)
+			if err != nil {
+				t.Fatalf("CheckOrder: %v", err)
+			}
+			if checked.Status != tt.want {
+				t.Errorf("status = %s, want %s", checked.Status, tt.want)
+			}
+			stored, err := store.PaymentGet(ctx, payment.ID)
+			if err != nil {
+				t.Fatalf("store.PaymentGet: %v", err)
+			}
+			if stored.Status != tt.want {
+				t.Errorf("stored status = %s, want %s", stored.Status, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v4"
+
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/models"
+)
+
+var ErrDriverLinked = errors.New("application is linked to another telegram account")
+
+const driverColumns = `id, telegram_id, name, phone, experience, status`
+
+const driverCreate = `
+INSERT INTO drivers (name, phone, experience) VALUES ($1, $2, $3)
+RETURNING ` + driverColumns + `;
+`
+
+const driverGetByID = `
+SELECT ` + driverColumns + `
+FROM drivers
+WHERE id = $1;
+`
+
+const driverGetByTelegramID = `
+SELECT ` + driverColumns + `
+FROM drivers
+WHERE telegram_id = $1;
+`
+
+const driverSetStatus = `
+UPDATE drivers
+SET status = $2
+WHERE id = $1
+RETURNING ` + driverColumns + `;
+`
+
+// driverLinkTelegram matches the application by the last ten digits of the
+// phone, so "8 (916) ..." and "+7916..." are treated as the same number. The
+// account is unlinked from its earlier application in the same statement.
+// An application linked to another account is left as is and returned, so
+// the caller can tell it from a missing one.
+const driverLinkTelegram = `
+WITH target AS (
+    SELECT id AS target_id, telegram_id AS linked_id
+    FROM drivers
+    WHERE right(regexp_replace(phone, '\D', '', 'g'), 10) = right(regexp_replace($1, '\D', '', 'g'), 10)
+    ORDER BY created_at DESC
+    LIMIT 1
+),
+linked AS (
+    UPDATE drivers
+    SET telegram_id = CASE WHEN id = target.target_id THEN $2::bigint END
+    FROM target
+    WHERE (target.linked_id IS NULL OR target.linked_id = $2)
+      AND (id = target.target_id OR telegram_id = $2)
+    RETURNING ` + driverColumns + `
+)
+SELECT ` + driverColumns + `
+FROM linked
+WHERE telegram_id = $2
+UNION ALL
+SELECT ` + driverColumns + `
+FROM drivers
+WHERE id = (SELECT target_id FROM target)
+  AND NOT EXISTS (SELECT 1 FROM linked);
+`
+
+func (s *Store) DriverCreate(ctx context.Context, name string, phone string, experience string) (*models.Driver, error) {
+	return s.queryDriver(ctx, driverCreate, name, phone, experience)
+}
+
+func (s *Store) DriverGetByID(ctx context.Context, driverID int) (*models.Driver, error) {
+	return s.queryDriver(ctx, driverGetByID, driverID)
+}
+
+func (s *Store) DriverGetByTelegramID(ctx context.Context, telegramID int64) (*models.Driver, error) {
+	return s.queryDriver(ctx, driverGetByTelegramID, telegramID)
+}
+
+func (s *Store) DriverSetStatus(ctx context.Context, driverID int, status models.DriverStatus) (*models.Driver, error) {
+	return s.queryDriver(ctx, driverSetStatus, driverID, status)
+}
+
+// DriverLinkTelegram binds the Telegram account to the latest application
+// with the same phone number. It returns ErrDriverLinked if the application
+// belongs to another account.
+func (s *Store) DriverLinkTelegram(ctx context.Context, phone string, telegramID int64) (*models.Driver, error) {
+	d, err := s.queryDriver(ctx, driverLinkTelegram, phone, telegramID)
+	if err != nil {
+		return nil, err
+	}
+	if d.TelegramID == nil || *d.TelegramID != telegramID {
+		return nil, ErrDriverLinked
+	}
+	return d, nil
+}
+
+func (s *Store) queryDriver(ctx context.Context, query string, args ...interface{}) (*models.Driver, error) {
+	rows, err := s.conn.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("conn.Query: %w", err)
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		if err = rows.Err(); err != nil {
+			return nil, fmt.Errorf("rows.Next: %w", err)
+		}
+		return nil, ErrNotFound
+	}
+
+	d := &models.Driver{}
+	if err = scanDriver(d, rows); err != nil {
+		return nil, fmt.Errorf("rows.Scan: %w", err)
+	}
+	return d, nil
+}
+
+func scanDriver(d *models.Driver, rows pgx.Rows) error {
+	return rows.Scan(
+		&d.ID,
+		&d.TelegramID,
+		&d.Name,
+		&d.Phone,
+		&d.Experience,
+		&d.Status,
+	)
+}
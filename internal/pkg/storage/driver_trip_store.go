@@ -0,0 +1,94 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/models"
+)
+
+// selectDriverTrip takes the event types and the commission payment
+// purpose and status as $1-$4, the queries below add their filters after.
+const selectDriverTrip = `
+SELECT o.id,
+       o.price,
+       o.price_per_hour,
+       (SELECT MIN(started.created_at)
+        FROM order_events started
+        WHERE started.order_id = o.id
+          AND started.type = $1),
+       finished.created_at,
+       EXISTS (SELECT 1
+               FROM payments p
+               WHERE p.order_id = o.id
+                 AND p.purpose = $3
+                 AND p.status = $4)
+FROM orders o
+         JOIN order_events finished ON finished.order_id = o.id AND finished.type = $2
+`
+
+const selectDriverTrips = selectDriverTrip + `
+WHERE o.driver_telegram_id = $5
+  AND finished.created_at >= $6
+ORDER BY finished.created_at;
+`
+
+const selectDriverTripByOrder = selectDriverTrip + `
+WHERE o.id = $5;
+`
+
+// DriverTrips returns the orders the driver finished since the given time.
+func (s *Store) DriverTrips(ctx context.Context, driverTelegramID int64, since time.Time) ([]models.DriverTrip, error) {
+	return s.queryDriverTrips(ctx, selectDriverTrips, driverTelegramID, since)
+}
+
+// DriverTripGet returns the trip of a finished order.
+func (s *Store) DriverTripGet(ctx context.Context, orderID int) (*models.DriverTrip, error) {
+	trips, err := s.queryDriverTrips(ctx, selectDriverTripByOrder, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if len(trips) == 0 {
+		return nil, ErrNotFound
+	}
+	return &trips[0], nil
+}
+
+func (s *Store) queryDriverTrips(ctx context.Context, query string, args ...interface{}) ([]models.DriverTrip, error) {
+	rows, err := s.conn.Query(
+		ctx,
+		query,
+		append([]interface{}{
+			models.OrderEventInProgress,
+			models.OrderEventFinished,
+			models.PaymentPurposeCommission,
+			models.PaymentStatusSucceeded,
+		}, args...)...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("conn.Query: %w", err)
+	}
+	defer rows.Close()
+
+	var result []models.DriverTrip
+	for rows.Next() {
+		t := models.DriverTrip{}
+		err = rows.Scan(
+			&t.OrderID,
+			&t.Price,
+			&t.PricePerHour,
+			&t.StartedAt,
+			&t.FinishedAt,
+			&t.CommissionPaid,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("rows.Scan: %w", err)
+		}
+		result = append(result, t)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows.Next: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,764 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/models"
+)
+
+// MemoryStore keeps everything in process memory. It is meant for tests and
+// local runs without Postgres.
+type MemoryStore struct {
+	mu           sync.Mutex
+	lastOrderID  int
+	lastDriverID int
+	lastPlaceID  int
+	orders       map[int]models.Order
+	users        map[int64]models.User
+	payments     map[uuid.UUID]models.Payment
+	drivers      map[int]models.Driver
+	events       []models.OrderEvent
+	tariffs      []models.Tariff
+	ratings      map[int]models.Rating
+	places       map[int]models.Place
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		orders:   make(map[int]models.Order),
+		users:    make(map[int64]models.User),
+		payments: make(map[uuid.UUID]models.Payment),
+		drivers:  make(map[int]models.Driver),
+		ratings:  make(map[int]models.Rating),
+		places:   make(map[int]models.Place),
+	}
+}
+
+func (s *MemoryStore) OrderCreate(ctx context.Context, userID uuid.UUID, telegramID int64) (*models.Order, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	o := s.insertOrder(models.Order{
+		UserID:     &userID,
+		TelegramID: telegramID,
+		Status:     models.OrderStatusDraft,
+	})
+	s.appendEvent(o.ID, models.OrderEventCreated, telegramID, nil)
+	return o, nil
+}
+
+func (s *MemoryStore) OrderCreateFromLambda(ctx context.Context, patch OrderPatch) (*models.Order, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	o := s.insertOrder(models.Order{
+		Source:       patch.Source,
+		Destination:  patch.Destination,
+		Time:         patch.Time,
+		Phone:        patch.Phone,
+		ScheduledAt:  patch.ScheduledAt,
+		TariffID:     patch.TariffID,
+		Price:        patch.Price,
+		PricePerHour: patch.PricePerHour,
+		Status:       models.OrderStatusAwaitingDriver,
+	})
+	s.appendEvent(o.ID, models.OrderEventCreated, 0, nil)
+	return o, nil
+}
+
+func (s *MemoryStore) OrderGet(ctx context.Context, userID uuid.UUID) (*models.Order, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var latest *models.Order
+	for _, o := range s.orders {
+		if o.UserID == nil || *o.UserID != userID {
+			continue
+		}
+		if latest == nil || o.ID > latest.ID {
+			o := o
+			latest = &o
+		}
+	}
+	if latest == nil {
+		return nil, ErrNotFound
+	}
+	return latest, nil
+}
+
+func (s *MemoryStore) OrderList(ctx context.Context, userID uuid.UUID, limit int, offset int) ([]models.Order, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var placed []models.Order
+	for _, o := range s.orders {
+		if o.UserID != nil && *o.UserID == userID && o.Status != models.OrderStatusDraft {
+			placed = append(placed, o)
+		}
+	}
+	sort.Slice(placed, func(i, j int) bool { return placed[i].ID > placed[j].ID })
+	if offset >= len(placed) {
+		return nil, nil
+	}
+	placed = placed[offset:]
+	if len(placed) > limit {
+		placed = placed[:limit]
+	}
+	return placed, nil
+}
+
+func (s *MemoryStore) OrderGetActive(ctx context.Context, userID uuid.UUID) (*models.Order, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var latest *models.Order
+	for _, o := range s.orders {
+		if o.UserID == nil || *o.UserID != userID {
+			continue
+		}
+		if o.Status == models.OrderStatusFinished || o.Status == models.OrderStatusCancelled {
+			continue
+		}
+		if latest == nil || o.ID > latest.ID {
+			o := o
+			latest = &o
+		}
+	}
+	if latest == nil {
+		return nil, ErrNotFound
+	}
+	return latest, nil
+}
+
+func (s *MemoryStore) OrderGetActiveByDriver(ctx context.Context, driverTelegramID int64) (*models.Order, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var latest *models.Order
+	for _, o := range s.orders {
+		if o.DriverTelegramID == nil || *o.DriverTelegramID != driverTelegramID {
+			continue
+		}
+		if o.Status == models.OrderStatusFinished || o.Status == models.OrderStatusCancelled {
+			continue
+		}
+		if latest == nil || o.ID > latest.ID {
+			o := o
+			latest = &o
+		}
+	}
+	if latest == nil {
+		return nil, ErrNotFound
+	}
+	return latest, nil
+}
+
+func (s *MemoryStore) OrderGetByID(ctx context.Context, orderID int) (*models.Order, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	o, ok := s.orders[orderID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &o, nil
+}
+
+func (s *MemoryStore) OrderUpdate(ctx context.Context, orderID int, patch OrderPatch) (*models.Order, error) {
+	if err := patch.Validate(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	o, ok := s.orders[orderID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if patch.Source != nil {
+		o.Source = patch.Source
+	}
+	if patch.Destination != nil {
+		o.Destination = patch.Destination
+	}
+	if patch.Time != nil {
+		o.Time = patch.Time
+	}
+	if patch.Phone != nil {
+		o.Phone = patch.Phone
+	}
+	if patch.ScheduledAt != nil {
+		o.ScheduledAt = patch.ScheduledAt
+	}
+	if patch.SourcePoint != nil || patch.ClearSourcePoint {
+		o.SourcePoint = patch.SourcePoint
+	}
+	if patch.DestinationPoint != nil || patch.ClearDestinationPoint {
+		o.DestinationPoint = patch.DestinationPoint
+	}
+	if patch.TariffID != nil {
+		o.TariffID = patch.TariffID
+	}
+	if patch.Price != nil {
+		o.Price = patch.Price
+	}
+	if patch.PricePerHour != nil {
+		o.PricePerHour = patch.PricePerHour
+	}
+	if patch.DriversChatMessageID != nil {
+		o.DriversChatMessageID = patch.DriversChatMessageID
+	}
+	if patch.FormStep != nil {
+		o.FormStep = patch.FormStep
+	}
+	s.orders[orderID] = o
+	return &o, nil
+}
+
+func (s *MemoryStore) OrderSetStatus(
+	ctx context.Context,
+	orderID int,
+	status models.OrderStatus,
+	actorID int64,
+) (*models.Order, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	o, ok := s.orders[orderID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if !canTransition(o.Status, status) {
+		return nil, &TransitionError{OrderID: orderID, From: o.Status, To: status}
+	}
+	s.appendEvent(orderID, models.OrderEventType(status), actorID, statusPayload(o.Status))
+	o.Status = status
+	s.orders[orderID] = o
+	return &o, nil
+}
+
+func (s *MemoryStore) OrderClaim(ctx context.Context, orderID int, driverTelegramID int64) (*models.Order, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	o, ok := s.orders[orderID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if o.DriverTelegramID != nil {
+		return nil, ErrAlreadyTaken
+	}
+	if o.Status != models.OrderStatusAwaitingDriver {
+		return nil, &TransitionError{OrderID: orderID, From: o.Status, To: models.OrderStatusAssigned}
+	}
+	s.appendEvent(orderID, models.OrderEventAssigned, driverTelegramID, statusPayload(o.Status))
+	o.DriverTelegramID = &driverTelegramID
+	o.Status = models.OrderStatusAssigned
+	s.orders[orderID] = o
+	return &o, nil
+}
+
+func (s *MemoryStore) OrderAddEvent(
+	ctx context.Context,
+	orderID int,
+	eventType models.OrderEventType,
+	actorID int64,
+	payload json.RawMessage,
+) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.orders[orderID]; !ok {
+		return ErrNotFound
+	}
+	s.appendEvent(orderID, eventType, actorID, payload)
+	return nil
+}
+
+func (s *MemoryStore) OrderEvents(ctx context.Context, orderID int) ([]models.OrderEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var result []models.OrderEvent
+	for _, e := range s.events {
+		if e.OrderID == orderID {
+			result = append(result, e)
+		}
+	}
+	return result, nil
+}
+
+func (s *MemoryStore) UserGet(ctx context.Context, telegramID int64) (*models.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.users[telegramID]
+	if !ok {
+		u = models.User{ID: uuid.New(), TelegramID: telegramID}
+		s.users[telegramID] = u
+	}
+	return &u, nil
+}
+
+func (s *MemoryStore) UserEnsureLanguage(ctx context.Context, telegramID int64, language string) (*models.User, error) {
+	return s.userSetLanguage(telegramID, language, false)
+}
+
+func (s *MemoryStore) UserSetLanguage(ctx context.Context, telegramID int64, language string) (*models.User, error) {
+	return s.userSetLanguage(telegramID, language, true)
+}
+
+func (s *MemoryStore) userSetLanguage(telegramID int64, language string, overwrite bool) (*models.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.users[telegramID]
+	if !ok {
+		u = models.User{ID: uuid.New(), TelegramID: telegramID}
+	}
+	if overwrite || u.Language == "" {
+		u.Language = language
+	}
+	s.users[telegramID] = u
+	return &u, nil
+}
+
+func (s *MemoryStore) UserSetPhone(ctx context.Context, telegramID int64, phone string) (*models.User, error) {
+	return s.updateUser(telegramID, func(u *models.User) { u.Phone = phone })
+}
+
+func (s *MemoryStore) UserSetName(ctx context.Context, telegramID int64, name string) (*models.User, error) {
+	return s.updateUser(telegramID, func(u *models.User) { u.Name = name })
+}
+
+func (s *MemoryStore) UserSetFormStep(ctx context.Context, telegramID int64, step string) (*models.User, error) {
+	return s.updateUser(telegramID, func(u *models.User) { u.FormStep = step })
+}
+
+func (s *MemoryStore) updateUser(telegramID int64, update func(u *models.User)) (*models.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.users[telegramID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	update(&u)
+	s.users[telegramID] = u
+	return &u, nil
+}
+
+func (s *MemoryStore) UserGetByID(ctx context.Context, userID uuid.UUID) (*models.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, u := range s.users {
+		if u.ID == userID {
+			return &u, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (s *MemoryStore) UserGetByTelegramID(ctx context.Context, telegramID int64) (*models.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.users[telegramID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &u, nil
+}
+
+func (s *MemoryStore) PaymentCreate(ctx context.Context, p models.Payment) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.payments[p.ID] = p
+	return nil
+}
+
+func (s *MemoryStore) PaymentSetStatus(ctx context.Context, paymentID uuid.UUID, status models.PaymentStatus) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.payments[paymentID]
+	if !ok {
+		return ErrNotFound
+	}
+	p.Status = status
+	s.payments[paymentID] = p
+	return nil
+}
+
+func (s *MemoryStore) PaymentGet(ctx context.Context, paymentID uuid.UUID) (*models.Payment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.payments[paymentID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &p, nil
+}
+
+func (s *MemoryStore) PaymentsForCheck(ctx context.Context) ([]models.Payment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var result []models.Payment
+	for _, p := range s.payments {
+		if p.Status == models.PaymentStatusPending {
+			result = append(result, p)
+		}
+	}
+	return result, nil
+}
+
+func (s *MemoryStore) DriverCreate(ctx context.Context, name string, phone string, experience string) (*models.Driver, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastDriverID++
+	d := models.Driver{
+		ID:         s.lastDriverID,
+		Name:       name,
+		Phone:      phone,
+		Experience: experience,
+		Status:     models.DriverStatusPending,
+	}
+	s.drivers[d.ID] = d
+	return &d, nil
+}
+
+func (s *MemoryStore) DriverGetByID(ctx context.Context, driverID int) (*models.Driver, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	d, ok := s.drivers[driverID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &d, nil
+}
+
+func (s *MemoryStore) DriverGetByTelegramID(ctx context.Context, telegramID int64) (*models.Driver, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, d := range s.drivers {
+		if d.TelegramID != nil && *d.TelegramID == telegramID {
+			return &d, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (s *MemoryStore) DriverSetStatus(ctx context.Context, driverID int, status models.DriverStatus) (*models.Driver, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	d, ok := s.drivers[driverID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	d.Status = status
+	s.drivers[driverID] = d
+	return &d, nil
+}
+
+func (s *MemoryStore) DriverLinkTelegram(ctx context.Context, phone string, telegramID int64) (*models.Driver, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var found *models.Driver
+	for _, d := range s.drivers {
+		if phoneSuffix(d.Phone) != phoneSuffix(phone) {
+			continue
+		}
+		if found == nil || d.ID > found.ID {
+			d := d
+			found = &d
+		}
+	}
+	if found == nil {
+		return nil, ErrNotFound
+	}
+	if found.TelegramID != nil && *found.TelegramID != telegramID {
+		return nil, ErrDriverLinked
+	}
+	for id, d := range s.drivers {
+		if d.TelegramID != nil && *d.TelegramID == telegramID {
+			d.TelegramID = nil
+			s.drivers[id] = d
+		}
+	}
+	found.TelegramID = &telegramID
+	s.drivers[found.ID] = *found
+	return found, nil
+}
+
+// AddTariff makes the tariff the active one.
+func (s *MemoryStore) AddTariff(t models.Tariff) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t.ID = len(s.tariffs) + 1
+	s.tariffs = append(s.tariffs, t)
+}
+
+func (s *MemoryStore) TariffGetActive(ctx context.Context) (*models.Tariff, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.tariffs) == 0 {
+		return nil, ErrNotFound
+	}
+	t := s.tariffs[len(s.tariffs)-1]
+	return &t, nil
+}
+
+func (s *MemoryStore) RatingCreate(ctx context.Context, orderID int, driverTelegramID int64, score int) (*models.Rating, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.ratings[orderID]; ok {
+		return nil, ErrAlreadyRated
+	}
+	r := models.Rating{OrderID: orderID, DriverTelegramID: driverTelegramID, Score: score}
+	s.ratings[orderID] = r
+	return &r, nil
+}
+
+func (s *MemoryStore) RatingGet(ctx context.Context, orderID int) (*models.Rating, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, ok := s.ratings[orderID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &r, nil
+}
+
+func (s *MemoryStore) RatingSetComment(ctx context.Context, orderID int, comment string) (*models.Rating, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, ok := s.ratings[orderID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	r.Comment = &comment
+	s.ratings[orderID] = r
+	return &r, nil
+}
+
+func (s *MemoryStore) DriverRating(ctx context.Context, driverTelegramID int64) (*models.DriverRating, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rating := &models.DriverRating{}
+	total := 0
+	for _, r := range s.ratings {
+		if r.DriverTelegramID == driverTelegramID {
+			total += r.Score
+			rating.Count++
+		}
+	}
+	if rating.Count != 0 {
+		rating.Average = float64(total) / float64(rating.Count)
+	}
+	return rating, nil
+}
+
+func (s *MemoryStore) DriverTrips(ctx context.Context, driverTelegramID int64, since time.Time) ([]models.DriverTrip, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var result []models.DriverTrip
+	for _, e := range s.events {
+		o := s.orders[e.OrderID]
+		if e.Type != models.OrderEventFinished || o.DriverTelegramID == nil || *o.DriverTelegramID != driverTelegramID {
+			continue
+		}
+		if e.CreatedAt.Before(since) {
+			continue
+		}
+		result = append(result, s.driverTrip(o, e.CreatedAt))
+	}
+	return result, nil
+}
+
+func (s *MemoryStore) DriverTripGet(ctx context.Context, orderID int) (*models.DriverTrip, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, e := range s.events {
+		if e.OrderID == orderID && e.Type == models.OrderEventFinished {
+			t := s.driverTrip(s.orders[orderID], e.CreatedAt)
+			return &t, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (s *MemoryStore) driverTrip(o models.Order, finishedAt time.Time) models.DriverTrip {
+	t := models.DriverTrip{
+		OrderID:      o.ID,
+		Price:        o.Price,
+		PricePerHour: o.PricePerHour,
+		FinishedAt:   finishedAt,
+	}
+	for _, started := range s.events {
+		if started.OrderID == o.ID && started.Type == models.OrderEventInProgress {
+			startedAt := started.CreatedAt
+			t.StartedAt = &startedAt
+			break
+		}
+	}
+	for _, p := range s.payments {
+		if p.OrderID == o.ID && p.Purpose == models.PaymentPurposeCommission && p.Status == models.PaymentStatusSucceeded {
+			t.CommissionPaid = true
+		}
+	}
+	return t
+}
+
+func (s *MemoryStore) PlaceList(ctx context.Context, userID uuid.UUID) ([]models.Place, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var result []models.Place
+	for _, p := range s.places {
+		if p.UserID == userID && p.Name != "" {
+			result = append(result, p)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result, nil
+}
+
+func (s *MemoryStore) PlaceCreate(
+	ctx context.Context,
+	userID uuid.UUID,
+	address string,
+	point *models.Point,
+) (*models.Place, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.deleteUnnamedPlaces(userID)
+	s.lastPlaceID++
+	p := models.Place{ID: s.lastPlaceID, UserID: userID, Address: address, Point: point}
+	s.places[p.ID] = p
+	return &p, nil
+}
+
+func (s *MemoryStore) PlaceGetUnnamed(ctx context.Context, userID uuid.UUID) (*models.Place, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, p := range s.places {
+		if p.UserID == userID && p.Name == "" {
+			return &p, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (s *MemoryStore) PlaceSetName(ctx context.Context, placeID int, name string) (*models.Place, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.places[placeID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	for id, other := range s.places {
+		if other.UserID == p.UserID && other.Name == name && id != placeID {
+			delete(s.places, id)
+		}
+	}
+	p.Name = name
+	s.places[placeID] = p
+	return &p, nil
+}
+
+func (s *MemoryStore) PlaceDelete(ctx context.Context, userID uuid.UUID, placeID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.places[placeID]
+	if !ok || p.UserID != userID {
+		return ErrNotFound
+	}
+	delete(s.places, placeID)
+	return nil
+}
+
+func (s *MemoryStore) PlaceDeleteUnnamed(ctx context.Context, userID uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.deleteUnnamedPlaces(userID)
+	return nil
+}
+
+func (s *MemoryStore) deleteUnnamedPlaces(userID uuid.UUID) {
+	for id, p := range s.places {
+		if p.UserID == userID && p.Name == "" {
+			delete(s.places, id)
+		}
+	}
+}
+
+func (s *MemoryStore) appendEvent(
+	orderID int,
+	eventType models.OrderEventType,
+	actorID int64,
+	payload json.RawMessage,
+) {
+	if payload == nil {
+		payload = json.RawMessage(`{}`)
+	}
+	s.events = append(s.events, models.OrderEvent{
+		ID:        int64(len(s.events) + 1),
+		OrderID:   orderID,
+		Type:      eventType,
+		ActorID:   actorID,
+		Payload:   payload,
+		CreatedAt: time.Now(),
+	})
+}
+
+func (s *MemoryStore) insertOrder(o models.Order) *models.Order {
+	s.lastOrderID++
+	o.ID = s.lastOrderID
+	o.CreatedAt = time.Now()
+	s.orders[o.ID] = o
+	return &o
+}
+
+// phoneSuffix mirrors the matching in driverLinkTelegram.
+func phoneSuffix(phone string) string {
+	digits := strings.Map(func(r rune) rune {
+		if r < '0' || r > '9' {
+			return -1
+		}
+		return r
+	}, phone)
+	if len(digits) > 10 {
+		return digits[len(digits)-10:]
+	}
+	return digits
+}
@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jackc/pgx/v4"
+
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/models"
+)
+
+const insertOrderEventQuery = `
+INSERT INTO order_events (order_id, type, actor_telegram_id, payload) VALUES ($1, $2, $3, $4);
+`
+
+const selectOrderEvents = `
+SELECT id, order_id, type, actor_telegram_id, payload, created_at
+FROM order_events
+WHERE order_id = $1
+ORDER BY created_at, id;
+`
+
+func (s *Store) OrderEvents(ctx context.Context, orderID int) ([]models.OrderEvent, error) {
+	rows, err := s.conn.Query(ctx, selectOrderEvents, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("conn.Query: %w", err)
+	}
+	defer rows.Close()
+
+	var result []models.OrderEvent
+	for rows.Next() {
+		e := models.OrderEvent{}
+		err = rows.Scan(
+			&e.ID,
+			&e.OrderID,
+			&e.Type,
+			&e.ActorID,
+			&e.Payload,
+			&e.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("rows.Scan: %w", err)
+		}
+		result = append(result, e)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows.Err: %w", err)
+	}
+	return result, nil
+}
+
+// OrderAddEvent appends an event that is not tied to a state change.
+func (s *Store) OrderAddEvent(
+	ctx context.Context,
+	orderID int,
+	eventType models.OrderEventType,
+	actorID int64,
+	payload json.RawMessage,
+) error {
+	return s.conn.BeginFunc(ctx, func(tx pgx.Tx) error {
+		return insertOrderEvent(ctx, tx, orderID, eventType, actorID, payload)
+	})
+}
+
+func insertOrderEvent(
+	ctx context.Context,
+	tx pgx.Tx,
+	orderID int,
+	eventType models.OrderEventType,
+	actorID int64,
+	payload json.RawMessage,
+) error {
+	if payload == nil {
+		payload = json.RawMessage(`{}`)
+	}
+	_, err := tx.Exec(ctx, insertOrderEventQuery, orderID, eventType, actorID, payload)
+	if err != nil {
+		return fmt.Errorf("tx.Exec(insertOrderEvent): %w", err)
+	}
+	return nil
+}
+
+func statusPayload(from models.OrderStatus) json.RawMessage {
+	payload, _ := json.Marshal(map[string]models.OrderStatus{"from": from})
+	return payload
+}
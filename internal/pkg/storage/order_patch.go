@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/models"
+)
+
+var ErrEmptyPatch = errors.New("order patch has no fields")
+
+// OrderPatch describes a partial order update. Nil fields are left as is.
+type OrderPatch struct {
+	Source      *string
+	Destination *string
+	Time        *string
+	Phone       *string
+	ScheduledAt *time.Time
+
+	SourcePoint      *models.Point
+	DestinationPoint *models.Point
+	// ClearSourcePoint and ClearDestinationPoint drop the point of an
+	// address typed instead of a shared location.
+	ClearSourcePoint      bool
+	ClearDestinationPoint bool
+
+	TariffID     *int
+	Price        *models.Money
+	PricePerHour *models.Money
+
+	DriversChatMessageID *int
+
+	FormStep *string
+}
+
+func (p OrderPatch) Validate() error {
+	fields := []struct {
+		name  string
+		value *string
+	}{
+		{"source", p.Source},
+		{"destination", p.Destination},
+		{"time", p.Time},
+		{"phone", p.Phone},
+		{"form step", p.FormStep},
+	}
+	if (p.Price != nil && *p.Price < 0) || (p.PricePerHour != nil && *p.PricePerHour < 0) {
+		return errors.New("order patch: price is negative")
+	}
+	if (p.ClearSourcePoint && p.SourcePoint != nil) || (p.ClearDestinationPoint && p.DestinationPoint != nil) {
+		return errors.New("order patch: point is both set and cleared")
+	}
+	empty := p.ScheduledAt == nil &&
+		p.SourcePoint == nil &&
+		p.DestinationPoint == nil &&
+		!p.ClearSourcePoint &&
+		!p.ClearDestinationPoint &&
+		p.TariffID == nil &&
+		p.Price == nil &&
+		p.PricePerHour == nil &&
+		p.DriversChatMessageID == nil
+	for _, f := range fields {
+		if f.value == nil {
+			continue
+		}
+		empty = false
+		if strings.TrimSpace(*f.value) == "" {
+			return fmt.Errorf("order patch: %s is blank", f.name)
+		}
+	}
+	if empty {
+		return ErrEmptyPatch
+	}
+	return nil
+}
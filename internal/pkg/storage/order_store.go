@@ -11,123 +11,380 @@ import (
 	"github.com/andrey-berenda/perfect-driver/internal/pkg/models"
 )
 
+const orderColumns = `id, user_id, source, destination, time, phone, telegram_id, status, driver_telegram_id, scheduled_at,
+tariff_id, price, price_per_hour, drivers_chat_message_id,
+source_latitude, source_longitude, destination_latitude, destination_longitude, form_step, created_at`
+
 const orderCreate = `
 INSERT INTO orders (user_id, telegram_id) VALUES ($1, $2)
-RETURNING id, user_id, source, destination, time, phone, telegram_id;
+RETURNING ` + orderColumns + `;
 `
 
 const orderCreateFromLambda = `
-INSERT INTO orders (source, destination, time, phone, telegram_id) VALUES ($1, $2, $3, $4, $5)
-RETURNING id, user_id, source, destination, time, phone, telegram_id;
+INSERT INTO orders (source, destination, time, phone, scheduled_at, tariff_id, price, price_per_hour, telegram_id, status)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING ` + orderColumns + `;
 `
 
 const orderGet = `
-SELECT id, user_id, source, destination, time, phone, telegram_id
+SELECT ` + orderColumns + `
 FROM orders
 WHERE user_id = $1
 ORDER BY created_at DESC;
 `
 
+const orderList = `
+SELECT ` + orderColumns + `
+FROM orders
+WHERE user_id = $1
+  AND status <> $2
+ORDER BY created_at DESC, id DESC
+LIMIT $3 OFFSET $4;
+`
+
+const orderGetActive = `
+SELECT ` + orderColumns + `
+FROM orders
+WHERE user_id = $1
+  AND status <> ALL ($2)
+ORDER BY created_at DESC
+LIMIT 1;
+`
+
+const orderGetActiveByDriver = `
+SELECT ` + orderColumns + `
+FROM orders
+WHERE driver_telegram_id = $1
+  AND status <> ALL ($2)
+ORDER BY created_at DESC
+LIMIT 1;
+`
+
 const orderGetByID = `
-SELECT id, user_id, source, destination, time, phone, telegram_id
+SELECT ` + orderColumns + `
 FROM orders
 WHERE id = $1
 ORDER BY created_at DESC;
 `
 
-const orderUpdateField = `
+const orderUpdate = `
+UPDATE orders
+SET source                  = COALESCE($2, source),
+    destination             = COALESCE($3, destination),
+    time                    = COALESCE($4, time),
+    phone                   = COALESCE($5, phone),
+    scheduled_at            = COALESCE($6, scheduled_at),
+    tariff_id               = COALESCE($7, tariff_id),
+    price                   = COALESCE($8, price),
+    price_per_hour          = COALESCE($9, price_per_hour),
+    drivers_chat_message_id = COALESCE($10, drivers_chat_message_id),
+    source_latitude         = CASE WHEN $16 THEN NULL ELSE COALESCE($11, source_latitude) END,
+    source_longitude        = CASE WHEN $16 THEN NULL ELSE COALESCE($12, source_longitude) END,
+    destination_latitude    = CASE WHEN $17 THEN NULL ELSE COALESCE($13, destination_latitude) END,
+    destination_longitude   = CASE WHEN $17 THEN NULL ELSE COALESCE($14, destination_longitude) END,
+    form_step               = COALESCE($15, form_step)
+WHERE id = $1
+RETURNING ` + orderColumns + `;
+`
+
+const orderGetForUpdate = `
+SELECT ` + orderColumns + `
+FROM orders
+WHERE id = $1
+FOR UPDATE;
+`
+
+const orderSetStatus = `
+UPDATE orders
+SET status = $2
+WHERE id = $1
+RETURNING ` + orderColumns + `;
+`
+
+const orderClaim = `
 UPDATE orders
-SET %s = $2
+SET driver_telegram_id = $2,
+    status    = $3
 WHERE id = $1
-RETURNING id, user_id, source, destination, time, phone, telegram_id;
+  AND driver_telegram_id IS NULL
+  AND status = $4
+RETURNING ` + orderColumns + `;
 `
 
 var ErrNotFound = errors.New("not found")
+var ErrAlreadyTaken = errors.New("order already taken")
 
-func (s *Store) OrderCreate(ctx context.Context, userID uuid.UUID, telegramID int64) (*models.Order, error) {
-	rows, err := s.conn.Query(ctx, orderCreate, userID, telegramID)
-	o := &models.Order{}
-	if err != nil {
-		return nil, fmt.Errorf("conn.Exec: %w", err)
+// orderTransitions lists the statuses an order may move to from each status.
+var orderTransitions = map[models.OrderStatus][]models.OrderStatus{
+	models.OrderStatusDraft:          {models.OrderStatusAwaitingDriver, models.OrderStatusCancelled},
+	models.OrderStatusAwaitingDriver: {models.OrderStatusAssigned, models.OrderStatusCancelled},
+	models.OrderStatusAssigned:       {models.OrderStatusDriverArrived, models.OrderStatusCancelled},
+	models.OrderStatusDriverArrived:  {models.OrderStatusInProgress, models.OrderStatusCancelled},
+	models.OrderStatusInProgress:     {models.OrderStatusFinished},
+}
+
+type TransitionError struct {
+	OrderID int
+	From    models.OrderStatus
+	To      models.OrderStatus
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("order %d: illegal transition from %s to %s", e.OrderID, e.From, e.To)
+}
+
+func canTransition(from models.OrderStatus, to models.OrderStatus) bool {
+	for _, next := range orderTransitions[from] {
+		if next == to {
+			return true
+		}
 	}
-	rows.Next()
+	return false
+}
 
-	err = scanOrder(o, rows)
-	rows.Close()
-	return o, err
+type querier interface {
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
 }
 
-func (s *Store) OrderCreateFromLambda(
-	ctx context.Context,
-	source string,
-	destination string,
-	time string,
-	phone string,
-) (*models.Order, error) {
-	rows, err := s.conn.Query(ctx, orderCreateFromLambda, source, destination, time, phone, 0)
-	o := &models.Order{}
+func (s *Store) OrderCreate(ctx context.Context, userID uuid.UUID, telegramID int64) (*models.Order, error) {
+	var o *models.Order
+	err := s.conn.BeginFunc(ctx, func(tx pgx.Tx) error {
+		var err error
+		o, err = queryOrder(ctx, tx, orderCreate, userID, telegramID)
+		if err != nil {
+			return err
+		}
+		return insertOrderEvent(ctx, tx, o.ID, models.OrderEventCreated, telegramID, nil)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("conn.Exec: %w", err)
+		return nil, err
 	}
-	rows.Next()
+	return o, nil
+}
 
-	err = scanOrder(o, rows)
-	rows.Close()
-	return o, err
+// OrderCreateFromLambda places an order from the site form. The patch carries
+// the form fields together with the quote, so the order reaches drivers
+// already priced.
+func (s *Store) OrderCreateFromLambda(ctx context.Context, patch OrderPatch) (*models.Order, error) {
+	var o *models.Order
+	err := s.conn.BeginFunc(ctx, func(tx pgx.Tx) error {
+		var err error
+		o, err = queryOrder(
+			ctx,
+			tx,
+			orderCreateFromLambda,
+			patch.Source,
+			patch.Destination,
+			patch.Time,
+			patch.Phone,
+			patch.ScheduledAt,
+			patch.TariffID,
+			patch.Price,
+			patch.PricePerHour,
+			0,
+			models.OrderStatusAwaitingDriver,
+		)
+		if err != nil {
+			return err
+		}
+		return insertOrderEvent(ctx, tx, o.ID, models.OrderEventCreated, 0, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return o, nil
 }
 
 func (s *Store) OrderGet(ctx context.Context, userID uuid.UUID) (*models.Order, error) {
-	rows, err := s.conn.Query(ctx, orderGet, userID)
-	o := &models.Order{}
+	return queryOrder(ctx, s.conn, orderGet, userID)
+}
+
+// OrderList returns a page of the user's placed orders, newest first.
+func (s *Store) OrderList(ctx context.Context, userID uuid.UUID, limit int, offset int) ([]models.Order, error) {
+	rows, err := s.conn.Query(ctx, orderList, userID, models.OrderStatusDraft, limit, offset)
 	if err != nil {
-		return nil, fmt.Errorf("conn.Exec: %w", err)
+		return nil, fmt.Errorf("conn.Query: %w", err)
 	}
-	if !rows.Next() {
-		return nil, errors.New("not found")
+	defer rows.Close()
+
+	var result []models.Order
+	for rows.Next() {
+		var o models.Order
+		if err = scanOrder(&o, rows); err != nil {
+			return nil, fmt.Errorf("rows.Scan: %w", err)
+		}
+		result = append(result, o)
 	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows.Next: %w", err)
+	}
+	return result, nil
+}
 
-	err = scanOrder(o, rows)
+// OrderGetActive returns the user's latest order that is neither finished
+// nor cancelled.
+func (s *Store) OrderGetActive(ctx context.Context, userID uuid.UUID) (*models.Order, error) {
+	return queryOrder(ctx, s.conn, orderGetActive, userID, []string{
+		string(models.OrderStatusFinished),
+		string(models.OrderStatusCancelled),
+	})
+}
+
+// OrderGetActiveByDriver returns the latest order the driver took that is
+// neither finished nor cancelled.
+func (s *Store) OrderGetActiveByDriver(ctx context.Context, driverTelegramID int64) (*models.Order, error) {
+	return queryOrder(ctx, s.conn, orderGetActiveByDriver, driverTelegramID, []string{
+		string(models.OrderStatusFinished),
+		string(models.OrderStatusCancelled),
+	})
+}
+
+func (s *Store) OrderGetByID(ctx context.Context, orderID int) (*models.Order, error) {
+	return queryOrder(ctx, s.conn, orderGetByID, orderID)
+}
+
+func (s *Store) OrderUpdate(ctx context.Context, orderID int, patch OrderPatch) (*models.Order, error) {
+	if err := patch.Validate(); err != nil {
+		return nil, err
+	}
+	sourceLatitude, sourceLongitude := pointArgs(patch.SourcePoint)
+	destinationLatitude, destinationLongitude := pointArgs(patch.DestinationPoint)
+	return queryOrder(
+		ctx,
+		s.conn,
+		orderUpdate,
+		orderID,
+		patch.Source,
+		patch.Destination,
+		patch.Time,
+		patch.Phone,
+		patch.ScheduledAt,
+		patch.TariffID,
+		patch.Price,
+		patch.PricePerHour,
+		patch.DriversChatMessageID,
+		sourceLatitude,
+		sourceLongitude,
+		destinationLatitude,
+		destinationLongitude,
+		patch.FormStep,
+		patch.ClearSourcePoint,
+		patch.ClearDestinationPoint,
+	)
+}
+
+// OrderSetStatus moves the order to the given status on behalf of actorID
+// and records the change in the order history. It returns a *TransitionError
+// if the order's current status does not allow the move.
+func (s *Store) OrderSetStatus(
+	ctx context.Context,
+	orderID int,
+	status models.OrderStatus,
+	actorID int64,
+) (*models.Order, error) {
+	var o *models.Order
+	err := s.conn.BeginFunc(ctx, func(tx pgx.Tx) error {
+		current, err := queryOrder(ctx, tx, orderGetForUpdate, orderID)
+		if err != nil {
+			return err
+		}
+		if !canTransition(current.Status, status) {
+			return &TransitionError{OrderID: orderID, From: current.Status, To: status}
+		}
+		o, err = queryOrder(ctx, tx, orderSetStatus, orderID, status)
+		if err != nil {
+			return err
+		}
+		return insertOrderEvent(ctx, tx, orderID, models.OrderEventType(status), actorID, statusPayload(current.Status))
+	})
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
-	rows.Close()
-	return o, err
+	return o, nil
 }
 
-func (s *Store) OrderGetByID(ctx context.Context, orderID int) (*models.Order, error) {
-	rows, err := s.conn.Query(ctx, orderGetByID, orderID)
-	o := &models.Order{}
+// OrderClaim assigns the order to the driver if no one has taken it yet.
+// Only one of concurrent callers succeeds, the rest get ErrAlreadyTaken.
+func (s *Store) OrderClaim(ctx context.Context, orderID int, driverTelegramID int64) (*models.Order, error) {
+	var o *models.Order
+	err := s.conn.BeginFunc(ctx, func(tx pgx.Tx) error {
+		current, err := queryOrder(ctx, tx, orderGetForUpdate, orderID)
+		if err != nil {
+			return err
+		}
+		if current.DriverTelegramID != nil {
+			return ErrAlreadyTaken
+		}
+		if current.Status != models.OrderStatusAwaitingDriver {
+			return &TransitionError{OrderID: orderID, From: current.Status, To: models.OrderStatusAssigned}
+		}
+		o, err = queryOrder(
+			ctx,
+			tx,
+			orderClaim,
+			orderID,
+			driverTelegramID,
+			models.OrderStatusAssigned,
+			models.OrderStatusAwaitingDriver,
+		)
+		if errors.Is(err, ErrNotFound) {
+			return ErrAlreadyTaken
+		}
+		if err != nil {
+			return err
+		}
+		return insertOrderEvent(
+			ctx,
+			tx,
+			orderID,
+			models.OrderEventAssigned,
+			driverTelegramID,
+			statusPayload(current.Status),
+		)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+func queryOrder(ctx context.Context, q querier, query string, args ...interface{}) (*models.Order, error) {
+	rows, err := q.Query(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("conn.Exec: %w", err)
+		return nil, fmt.Errorf("conn.Query: %w", err)
 	}
+	defer rows.Close()
 	if !rows.Next() {
+		if err = rows.Err(); err != nil {
+			return nil, fmt.Errorf("rows.Next: %w", err)
+		}
 		return nil, ErrNotFound
 	}
 
-	err = scanOrder(o, rows)
-	if err != nil {
-		panic(err)
+	o := &models.Order{}
+	if err = scanOrder(o, rows); err != nil {
+		return nil, fmt.Errorf("rows.Scan: %w", err)
 	}
-	rows.Close()
-	return o, err
+	return o, nil
 }
 
-func (s *Store) OrderSetField(ctx context.Context, orderID int, field string, value string) (*models.Order, error) {
-	q := fmt.Sprintf(orderUpdateField, field)
-	rows, err := s.conn.Query(ctx, q, orderID, value)
-	o := &models.Order{}
-	if err != nil {
-		return nil, fmt.Errorf("conn.Exec: %w", err)
+func pointArgs(p *models.Point) (*float64, *float64) {
+	if p == nil {
+		return nil, nil
 	}
-	rows.Next()
+	return &p.Latitude, &p.Longitude
+}
 
-	err = scanOrder(o, rows)
-	rows.Close()
-	return o, err
+func scanPoint(latitude *float64, longitude *float64) *models.Point {
+	if latitude == nil || longitude == nil {
+		return nil
+	}
+	return &models.Point{Latitude: *latitude, Longitude: *longitude}
 }
 
 func scanOrder(o *models.Order, rows pgx.Rows) error {
-	return rows.Scan(
+	var sourceLatitude, sourceLongitude, destinationLatitude, destinationLongitude *float64
+	err := rows.Scan(
 		&o.ID,
 		&o.UserID,
 		&o.Source,
@@ -135,5 +392,24 @@ func scanOrder(o *models.Order, rows pgx.Rows) error {
 		&o.Time,
 		&o.Phone,
 		&o.TelegramID,
+		&o.Status,
+		&o.DriverTelegramID,
+		&o.ScheduledAt,
+		&o.TariffID,
+		&o.Price,
+		&o.PricePerHour,
+		&o.DriversChatMessageID,
+		&sourceLatitude,
+		&sourceLongitude,
+		&destinationLatitude,
+		&destinationLongitude,
+		&o.FormStep,
+		&o.CreatedAt,
 	)
+	if err != nil {
+		return err
+	}
+	o.SourcePoint = scanPoint(sourceLatitude, sourceLongitude)
+	o.DestinationPoint = scanPoint(destinationLatitude, destinationLongitude)
+	return nil
 }
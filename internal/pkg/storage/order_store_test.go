@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/models"
+)
+
+func TestCanTransition(t *testing.T) {
+	statuses := []models.OrderStatus{
+		models.OrderStatusDraft,
+		models.OrderStatusAwaitingDriver,
+		models.OrderStatusAssigned,
+		models.OrderStatusDriverArrived,
+		models.OrderStatusInProgress,
+		models.OrderStatusFinished,
+		models.OrderStatusCancelled,
+	}
+	type move struct{ from, to models.OrderStatus }
+	allowed := map[move]bool{
+		{models.OrderStatusDraft, models.OrderStatusAwaitingDriver}:     true,
+		{models.OrderStatusDraft, models.OrderStatusCancelled}:          true,
+		{models.OrderStatusAwaitingDriver, models.OrderStatusAssigned}:  true,
+		{models.OrderStatusAwaitingDriver, models.OrderStatusCancelled}: true,
+		{models.OrderStatusAssigned, models.OrderStatusDriverArrived}:   true,
+		{models.OrderStatusAssigned, models.OrderStatusCancelled}:       true,
+		{models.OrderStatusDriverArrived, models.OrderStatusInProgress}: true,
+		{models.OrderStatusDriverArrived, models.OrderStatusCancelled}:  true,
+		{models.OrderStatusInProgress, models.OrderStatusFinished}:      true,
+	}
+	for _, from := range statuses {
+		for _, to := range statuses {
+			want := allowed[move{from, to}]
+			if got := canTransition(from, to); got != want {
+				t.Errorf("canTransition(%s, %s) = %t, want %t", from, to, got, want)
+			}
+		}
+	}
+}
+
+func TestMemoryStoreOrderSetStatus(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore()
+	order, err := s.OrderCreate(ctx, uuid.New(), 1)
+	if err != nil {
+		t.Fatalf("OrderCreate: %v", err)
+	}
+
+	_, err = s.OrderSetStatus(ctx, order.ID, models.OrderStatusFinished, 1)
+	var transitionErr *TransitionError
+	if !errors.As(err, &transitionErr) {
+		t.Fatalf("OrderSetStatus(finished) error = %v, want a *TransitionError", err)
+	}
+	if transitionErr.From != models.OrderStatusDraft || transitionErr.To != models.OrderStatusFinished {
+		t.Errorf("TransitionError = %+v, want from draft to finished", transitionErr)
+	}
+
+	updated, err := s.OrderSetStatus(ctx, order.ID, models.OrderStatusAwaitingDriver, 1)
+	if err != nil {
+		t.Fatalf("OrderSetStatus(awaiting_driver): %v", err)
+	}
+	if updated.Status != models.OrderStatusAwaitingDriver {
+		t.Errorf("status = %s, want %s", updated.Status, models.OrderStatusAwaitingDriver)
+	}
+	events, err := s.OrderEvents(ctx, order.ID)
+	if err != nil {
+		t.Fatalf("OrderEvents: %v", err)
+	}
+	if len(events) == 0 || events[len(events)-1].Type != models.OrderEventType(models.OrderStatusAwaitingDriver) {
+		t.Errorf("events = %+v, want the status change last", events)
+	}
+}
@@ -16,13 +16,14 @@ INSERT INTO payments (
 	  id, 
       order_id,
 	  status,
-	  confirmation_url
-) VALUES ($1, $2, $3, $4)
-RETURNING id, status, order_id, confirmation_url;
+	  confirmation_url,
+	  purpose
+) VALUES ($1, $2, $3, $4, $5)
+RETURNING id, status, order_id, confirmation_url, purpose;
 `
 
 const selectPaymentByID = `
-SELECT id, status, order_id, confirmation_url
+SELECT id, status, order_id, confirmation_url, purpose
 FROM payments
 WHERE id = $1;
 `
@@ -34,7 +35,7 @@ WHERE id = $1;
 `
 
 const selectPendingPayments = `
-SELECT id, status, order_id, confirmation_url
+SELECT id, status, order_id, confirmation_url, purpose
 FROM payments
 WHERE status = $1;
 `
@@ -47,6 +48,7 @@ func (s *Store) PaymentCreate(ctx context.Context, p models.Payment) error {
 		p.OrderID,
 		p.Status,
 		p.ConfirmationURL,
+		p.Purpose,
 	)
 	if err != nil {
 		return fmt.Errorf("conn.Query: %w", err)
@@ -148,6 +150,7 @@ func scanPayment(rows pgx.Rows) (models.Payment, error) {
 		&p.Status,
 		&p.OrderID,
 		&p.ConfirmationURL,
+		&p.Purpose,
 	)
 	if err != nil {
 		return p, fmt.Errorf("rows.Scan: %w", err)
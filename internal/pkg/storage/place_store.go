@@ -0,0 +1,180 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/models"
+)
+
+const placeColumns = `id, user_id, name, address, latitude, longitude`
+
+const placeList = `
+SELECT ` + placeColumns + `
+FROM places
+WHERE user_id = $1
+  AND name IS NOT NULL
+ORDER BY created_at, id;
+`
+
+const placeCreate = `
+INSERT INTO places (user_id, address, latitude, longitude) VALUES ($1, $2, $3, $4)
+RETURNING ` + placeColumns + `;
+`
+
+const placeGetUnnamed = `
+SELECT ` + placeColumns + `
+FROM places
+WHERE user_id = $1
+  AND name IS NULL
+ORDER BY id DESC
+LIMIT 1;
+`
+
+const placeDeleteSameName = `
+DELETE
+FROM places
+WHERE user_id = (SELECT user_id FROM places WHERE id = $1)
+  AND name = $2
+  AND id <> $1;
+`
+
+const placeSetName = `
+UPDATE places
+SET name = $2
+WHERE id = $1
+RETURNING ` + placeColumns + `;
+`
+
+const placeDelete = `
+DELETE
+FROM places
+WHERE user_id = $1
+  AND id = $2;
+`
+
+const placeDeleteUnnamed = `
+DELETE
+FROM places
+WHERE user_id = $1
+  AND name IS NULL;
+`
+
+func (s *Store) PlaceList(ctx context.Context, userID uuid.UUID) ([]models.Place, error) {
+	rows, err := s.conn.Query(ctx, placeList, userID)
+	if err != nil {
+		return nil, fmt.Errorf("conn.Query: %w", err)
+	}
+	defer rows.Close()
+
+	var result []models.Place
+	for rows.Next() {
+		var p models.Place
+		if err = scanPlace(&p, rows); err != nil {
+			return nil, fmt.Errorf("rows.Scan: %w", err)
+		}
+		result = append(result, p)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows.Next: %w", err)
+	}
+	return result, nil
+}
+
+func (s *Store) PlaceCreate(
+	ctx context.Context,
+	userID uuid.UUID,
+	address string,
+	point *models.Point,
+) (*models.Place, error) {
+	var p *models.Place
+	err := s.conn.BeginFunc(ctx, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, placeDeleteUnnamed, userID)
+		if err != nil {
+			return fmt.Errorf("tx.Exec: %w", err)
+		}
+		latitude, longitude := pointArgs(point)
+		p, err = queryPlace(ctx, tx, placeCreate, userID, address, latitude, longitude)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (s *Store) PlaceGetUnnamed(ctx context.Context, userID uuid.UUID) (*models.Place, error) {
+	return queryPlace(ctx, s.conn, placeGetUnnamed, userID)
+}
+
+func (s *Store) PlaceSetName(ctx context.Context, placeID int, name string) (*models.Place, error) {
+	var p *models.Place
+	err := s.conn.BeginFunc(ctx, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, placeDeleteSameName, placeID, name)
+		if err != nil {
+			return fmt.Errorf("tx.Exec: %w", err)
+		}
+		p, err = queryPlace(ctx, tx, placeSetName, placeID, name)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (s *Store) PlaceDelete(ctx context.Context, userID uuid.UUID, placeID int) error {
+	tag, err := s.conn.Exec(ctx, placeDelete, userID, placeID)
+	if err != nil {
+		return fmt.Errorf("conn.Exec: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *Store) PlaceDeleteUnnamed(ctx context.Context, userID uuid.UUID) error {
+	_, err := s.conn.Exec(ctx, placeDeleteUnnamed, userID)
+	if err != nil {
+		return fmt.Errorf("conn.Exec: %w", err)
+	}
+	return nil
+}
+
+func queryPlace(ctx context.Context, q querier, query string, args ...interface{}) (*models.Place, error) {
+	rows, err := q.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("conn.Query: %w", err)
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		if err = rows.Err(); err != nil {
+			return nil, fmt.Errorf("rows.Next: %w", err)
+		}
+		return nil, ErrNotFound
+	}
+
+	p := &models.Place{}
+	if err = scanPlace(p, rows); err != nil {
+		return nil, fmt.Errorf("rows.Scan: %w", err)
+	}
+	return p, nil
+}
+
+func scanPlace(p *models.Place, rows pgx.Rows) error {
+	var name *string
+	var latitude, longitude *float64
+	err := rows.Scan(&p.ID, &p.UserID, &name, &p.Address, &latitude, &longitude)
+	if err != nil {
+		return err
+	}
+	if name != nil {
+		p.Name = *name
+	}
+	p.Point = scanPoint(latitude, longitude)
+	return nil
+}
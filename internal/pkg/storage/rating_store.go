@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/models"
+)
+
+var ErrAlreadyRated = errors.New("order already rated")
+
+const ratingColumns = `order_id, driver_telegram_id, score, comment`
+
+const ratingCreate = `
+INSERT INTO ratings (order_id, driver_telegram_id, score) VALUES ($1, $2, $3)
+ON CONFLICT (order_id) DO NOTHING
+RETURNING ` + ratingColumns + `;
+`
+
+const ratingGet = `
+SELECT ` + ratingColumns + `
+FROM ratings
+WHERE order_id = $1;
+`
+
+const ratingSetComment = `
+UPDATE ratings
+SET comment = $2
+WHERE order_id = $1
+RETURNING ` + ratingColumns + `;
+`
+
+const driverRating = `
+SELECT COALESCE(AVG(score), 0)::float8, COUNT(*)
+FROM ratings
+WHERE driver_telegram_id = $1;
+`
+
+// RatingCreate stores the score of the order. Each order can be rated once,
+// later calls get ErrAlreadyRated.
+func (s *Store) RatingCreate(ctx context.Context, orderID int, driverTelegramID int64, score int) (*models.Rating, error) {
+	r, err := s.queryRating(ctx, ratingCreate, orderID, driverTelegramID, score)
+	if errors.Is(err, ErrNotFound) {
+		return nil, ErrAlreadyRated
+	}
+	return r, err
+}
+
+func (s *Store) RatingGet(ctx context.Context, orderID int) (*models.Rating, error) {
+	return s.queryRating(ctx, ratingGet, orderID)
+}
+
+func (s *Store) RatingSetComment(ctx context.Context, orderID int, comment string) (*models.Rating, error) {
+	return s.queryRating(ctx, ratingSetComment, orderID, comment)
+}
+
+func (s *Store) DriverRating(ctx context.Context, driverTelegramID int64) (*models.DriverRating, error) {
+	r := &models.DriverRating{}
+	err := s.conn.QueryRow(ctx, driverRating, driverTelegramID).Scan(&r.Average, &r.Count)
+	if err != nil {
+		return nil, fmt.Errorf("conn.QueryRow: %w", err)
+	}
+	return r, nil
+}
+
+func (s *Store) queryRating(ctx context.Context, query string, args ...interface{}) (*models.Rating, error) {
+	rows, err := s.conn.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("conn.Query: %w", err)
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		if err = rows.Err(); err != nil {
+			return nil, fmt.Errorf("rows.Next: %w", err)
+		}
+		return nil, ErrNotFound
+	}
+
+	r := &models.Rating{}
+	err = rows.Scan(&r.OrderID, &r.DriverTelegramID, &r.Score, &r.Comment)
+	if err != nil {
+		return nil, fmt.Errorf("rows.Scan: %w", err)
+	}
+	return r, nil
+}
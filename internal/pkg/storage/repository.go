@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/models"
+)
+
+type OrderRepository interface {
+	OrderCreate(ctx context.Context, userID uuid.UUID, telegramID int64) (*models.Order, error)
+	OrderCreateFromLambda(ctx context.Context, patch OrderPatch) (*models.Order, error)
+	OrderGet(ctx context.Context, userID uuid.UUID) (*models.Order, error)
+	OrderList(ctx context.Context, userID uuid.UUID, limit int, offset int) ([]models.Order, error)
+	OrderGetActive(ctx context.Context, userID uuid.UUID) (*models.Order, error)
+	OrderGetActiveByDriver(ctx context.Context, driverTelegramID int64) (*models.Order, error)
+	OrderGetByID(ctx context.Context, orderID int) (*models.Order, error)
+	OrderUpdate(ctx context.Context, orderID int, patch OrderPatch) (*models.Order, error)
+	OrderSetStatus(ctx context.Context, orderID int, status models.OrderStatus, actorID int64) (*models.Order, error)
+	OrderClaim(ctx context.Context, orderID int, driverTelegramID int64) (*models.Order, error)
+	OrderEvents(ctx context.Context, orderID int) ([]models.OrderEvent, error)
+	OrderAddEvent(
+		ctx context.Context,
+		orderID int,
+		eventType models.OrderEventType,
+		actorID int64,
+		payload json.RawMessage,
+	) error
+}
+
+type UserRepository interface {
+	UserGet(ctx context.Context, telegramID int64) (*models.User, error)
+	UserGetByID(ctx context.Context, userID uuid.UUID) (*models.User, error)
+	UserGetByTelegramID(ctx context.Context, telegramID int64) (*models.User, error)
+	// UserEnsureLanguage sets the language only if the user has none yet.
+	UserEnsureLanguage(ctx context.Context, telegramID int64, language string) (*models.User, error)
+	UserSetLanguage(ctx context.Context, telegramID int64, language string) (*models.User, error)
+	UserSetPhone(ctx context.Context, telegramID int64, phone string) (*models.User, error)
+	UserSetName(ctx context.Context, telegramID int64, name string) (*models.User, error)
+	UserSetFormStep(ctx context.Context, telegramID int64, step string) (*models.User, error)
+}
+
+type PaymentRepository interface {
+	PaymentCreate(ctx context.Context, p models.Payment) error
+	PaymentSetStatus(ctx context.Context, paymentID uuid.UUID, status models.PaymentStatus) error
+	PaymentGet(ctx context.Context, paymentID uuid.UUID) (*models.Payment, error)
+	PaymentsForCheck(ctx context.Context) ([]models.Payment, error)
+}
+
+type DriverRepository interface {
+	DriverCreate(ctx context.Context, name string, phone string, experience string) (*models.Driver, error)
+	DriverGetByID(ctx context.Context, driverID int) (*models.Driver, error)
+	DriverGetByTelegramID(ctx context.Context, telegramID int64) (*models.Driver, error)
+	DriverSetStatus(ctx context.Context, driverID int, status models.DriverStatus) (*models.Driver, error)
+	DriverLinkTelegram(ctx context.Context, phone string, telegramID int64) (*models.Driver, error)
+	DriverTrips(ctx context.Context, driverTelegramID int64, since time.Time) ([]models.DriverTrip, error)
+	DriverTripGet(ctx context.Context, orderID int) (*models.DriverTrip, error)
+}
+
+type TariffRepository interface {
+	TariffGetActive(ctx context.Context) (*models.Tariff, error)
+}
+
+type RatingRepository interface {
+	RatingCreate(ctx context.Context, orderID int, driverTelegramID int64, score int) (*models.Rating, error)
+	RatingGet(ctx context.Context, orderID int) (*models.Rating, error)
+	RatingSetComment(ctx context.Context, orderID int, comment string) (*models.Rating, error)
+	DriverRating(ctx context.Context, driverTelegramID int64) (*models.DriverRating, error)
+}
+
+type PlaceRepository interface {
+	// PlaceList returns the places the user has named.
+	PlaceList(ctx context.Context, userID uuid.UUID) ([]models.Place, error)
+	// PlaceCreate adds a place without a name. An earlier place of the user
+	// that is still not named is removed.
+	PlaceCreate(ctx context.Context, userID uuid.UUID, address string, point *models.Point) (*models.Place, error)
+	PlaceGetUnnamed(ctx context.Context, userID uuid.UUID) (*models.Place, error)
+	// PlaceSetName names the place. A place of the user with the same name
+	// is replaced.
+	PlaceSetName(ctx context.Context, placeID int, name string) (*models.Place, error)
+	PlaceDelete(ctx context.Context, userID uuid.UUID, placeID int) error
+	PlaceDeleteUnnamed(ctx context.Context, userID uuid.UUID) error
+}
+
+type Repository interface {
+	OrderRepository
+	UserRepository
+	PaymentRepository
+	DriverRepository
+	TariffRepository
+	RatingRepository
+	PlaceRepository
+}
+
+var _ Repository = (*Store)(nil)
+var _ Repository = (*MemoryStore)(nil)
@@ -0,0 +1,45 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/andrey-berenda/perfect-driver/internal/pkg/models"
+)
+
+const tariffGetActive = `
+SELECT id, name, base_fare, per_hour, night_surcharge, night_start, night_end
+FROM tariffs
+WHERE active
+ORDER BY created_at DESC
+LIMIT 1;
+`
+
+func (s *Store) TariffGetActive(ctx context.Context) (*models.Tariff, error) {
+	rows, err := s.conn.Query(ctx, tariffGetActive)
+	if err != nil {
+		return nil, fmt.Errorf("conn.Query: %w", err)
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		if err = rows.Err(); err != nil {
+			return nil, fmt.Errorf("rows.Next: %w", err)
+		}
+		return nil, ErrNotFound
+	}
+
+	t := &models.Tariff{}
+	err = rows.Scan(
+		&t.ID,
+		&t.Name,
+		&t.BaseFare,
+		&t.PerHour,
+		&t.NightSurcharge,
+		&t.NightStart,
+		&t.NightEnd,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("rows.Scan: %w", err)
+	}
+	return t, nil
+}
@@ -10,47 +10,132 @@ import (
 	"github.com/andrey-berenda/perfect-driver/internal/pkg/models"
 )
 
+const userColumns = `id, telegram_id, language, phone, name, form_step`
+
 const upsertUser = `
 INSERT INTO users (telegram_id) VALUES ($1)
 ON CONFLICT (telegram_id) DO UPDATE SET telegram_id = $1
-RETURNING id, telegram_id;
+RETURNING ` + userColumns + `;
 `
 
 const selectUser = `
-SELECT id, telegram_id
+SELECT ` + userColumns + `
 FROM users
 WHERE id = $1;
 `
 
-func (s *Store) UserGet(ctx context.Context, telegramID int64) (*models.User, error) {
-	rows, err := s.conn.Query(ctx, upsertUser, telegramID)
-	u := &models.User{}
-	if err != nil {
-		return nil, fmt.Errorf("conn.Exec: %w", err)
-	}
-	rows.Next()
+const selectUserByTelegramID = `
+SELECT ` + userColumns + `
+FROM users
+WHERE telegram_id = $1;
+`
 
-	err = scanUser(u, rows)
-	rows.Close()
-	return u, err
+// upsertUserLanguage keeps the language the user already has unless
+// overwrite ($3) is set.
+const upsertUserLanguage = `
+INSERT INTO users (telegram_id, language) VALUES ($1, $2)
+ON CONFLICT (telegram_id) DO UPDATE
+SET language = CASE WHEN $3 OR users.language IS NULL THEN EXCLUDED.language ELSE users.language END
+RETURNING ` + userColumns + `;
+`
+
+const updateUserPhone = `
+UPDATE users
+SET phone = $2
+WHERE telegram_id = $1
+RETURNING ` + userColumns + `;
+`
+
+const updateUserName = `
+UPDATE users
+SET name = $2
+WHERE telegram_id = $1
+RETURNING ` + userColumns + `;
+`
+
+const updateUserFormStep = `
+UPDATE users
+SET form_step = NULLIF($2, '')
+WHERE telegram_id = $1
+RETURNING ` + userColumns + `;
+`
+
+func (s *Store) UserGet(ctx context.Context, telegramID int64) (*models.User, error) {
+	return s.queryUser(ctx, upsertUser, telegramID)
 }
 
 func (s *Store) UserGetByID(ctx context.Context, userID uuid.UUID) (*models.User, error) {
-	rows, err := s.conn.Query(ctx, selectUser, userID)
-	u := &models.User{}
+	return s.queryUser(ctx, selectUser, userID)
+}
+
+// UserGetByTelegramID is like UserGet but does not create the user: it
+// returns ErrNotFound for someone who never wrote to the customer bot.
+func (s *Store) UserGetByTelegramID(ctx context.Context, telegramID int64) (*models.User, error) {
+	return s.queryUser(ctx, selectUserByTelegramID, telegramID)
+}
+
+func (s *Store) UserEnsureLanguage(ctx context.Context, telegramID int64, language string) (*models.User, error) {
+	return s.queryUser(ctx, upsertUserLanguage, telegramID, language, false)
+}
+
+func (s *Store) UserSetLanguage(ctx context.Context, telegramID int64, language string) (*models.User, error) {
+	return s.queryUser(ctx, upsertUserLanguage, telegramID, language, true)
+}
+
+func (s *Store) UserSetPhone(ctx context.Context, telegramID int64, phone string) (*models.User, error) {
+	return s.queryUser(ctx, updateUserPhone, telegramID, phone)
+}
+
+func (s *Store) UserSetName(ctx context.Context, telegramID int64, name string) (*models.User, error) {
+	return s.queryUser(ctx, updateUserName, telegramID, name)
+}
+
+// UserSetFormStep stores the position of the profile form, "" clears it.
+func (s *Store) UserSetFormStep(ctx context.Context, telegramID int64, step string) (*models.User, error) {
+	return s.queryUser(ctx, updateUserFormStep, telegramID, step)
+}
+
+func (s *Store) queryUser(ctx context.Context, query string, args ...interface{}) (*models.User, error) {
+	rows, err := s.conn.Query(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("conn.Exec: %w", err)
+		return nil, fmt.Errorf("conn.Query: %w", err)
 	}
 	defer rows.Close()
-	rows.Next()
+	if !rows.Next() {
+		if err = rows.Err(); err != nil {
+			return nil, fmt.Errorf("rows.Next: %w", err)
+		}
+		return nil, ErrNotFound
+	}
 
-	err = scanUser(u, rows)
-	return u, err
+	u := &models.User{}
+	if err = scanUser(u, rows); err != nil {
+		return nil, fmt.Errorf("rows.Scan: %w", err)
+	}
+	return u, nil
 }
 
 func scanUser(u *models.User, rows pgx.Rows) error {
-	return rows.Scan(
+	var language, phone, name, formStep *string
+	err := rows.Scan(
 		&u.ID,
 		&u.TelegramID,
+		&language,
+		&phone,
+		&name,
+		&formStep,
 	)
+	if language != nil {
+		u.Language = *language
+	}
+	if phone != nil {
+		u.Phone = *phone
+	}
+	if name != nil {
+		u.Name = *name
+	}
+	if formStep != nil {
+		u.FormStep = *formStep
+	}
+	return err
 }
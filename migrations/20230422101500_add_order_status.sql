@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE orders
+    ADD COLUMN status text NOT NULL DEFAULT 'draft'
+        CHECK (status IN (
+                          'draft',
+                          'awaiting_driver',
+                          'assigned',
+                          'driver_arrived',
+                          'in_progress',
+                          'finished',
+                          'cancelled'
+            ));
+
+UPDATE orders
+SET status = 'awaiting_driver'
+WHERE phone IS NOT NULL;
+
+CREATE INDEX ON orders (status);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE orders
+    DROP COLUMN status;
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE orders
+    ADD COLUMN driver_telegram_id BIGINT;
+
+CREATE INDEX ON orders (driver_telegram_id);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE orders
+    DROP COLUMN driver_telegram_id;
+-- +goose StatementEnd
@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE drivers
+(
+    id          BIGSERIAL PRIMARY KEY,
+    telegram_id BIGINT,
+    name        text        NOT NULL,
+    phone       text        NOT NULL,
+    experience  text        NOT NULL DEFAULT '',
+    status      text        NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'approved', 'blocked')),
+    updated_at  timestamptz NOT NULL DEFAULT now(),
+    created_at  timestamptz NOT NULL DEFAULT now(),
+    -- Relinking moves telegram_id from one application to another in a
+    -- single UPDATE, so uniqueness is checked at the end of the statement.
+    CONSTRAINT drivers_telegram_id_key UNIQUE (telegram_id) DEFERRABLE INITIALLY IMMEDIATE
+);
+CREATE INDEX ON drivers (phone);
+
+CREATE TRIGGER mdt_drivers
+    BEFORE UPDATE ON drivers
+    FOR EACH ROW
+EXECUTE PROCEDURE moddatetime (updated_at);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE drivers;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE order_events
+(
+    id                BIGSERIAL PRIMARY KEY,
+    order_id          BIGINT      NOT NULL references orders (id),
+    type              text        NOT NULL,
+    actor_telegram_id BIGINT      NOT NULL DEFAULT 0,
+    payload           JSONB       NOT NULL DEFAULT '{}',
+    created_at        timestamptz NOT NULL DEFAULT now()
+);
+CREATE INDEX ON order_events (order_id, created_at);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE order_events;
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE orders
+    ADD COLUMN scheduled_at timestamptz;
+
+CREATE INDEX ON orders (scheduled_at);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE orders
+    DROP COLUMN scheduled_at;
+-- +goose StatementEnd
@@ -0,0 +1,38 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE tariffs
+(
+    id              BIGSERIAL PRIMARY KEY,
+    name            text        NOT NULL,
+    -- Amounts are in kopecks.
+    base_fare       BIGINT      NOT NULL,
+    per_hour        BIGINT      NOT NULL DEFAULT 0,
+    night_surcharge BIGINT      NOT NULL DEFAULT 0,
+    -- Night hours are [night_start, night_end) in the city timezone.
+    night_start     SMALLINT    NOT NULL DEFAULT 0 CHECK (night_start BETWEEN 0 AND 23),
+    night_end       SMALLINT    NOT NULL DEFAULT 6 CHECK (night_end BETWEEN 0 AND 23),
+    active          boolean     NOT NULL DEFAULT true,
+    created_at      timestamptz NOT NULL DEFAULT now()
+);
+
+-- The same 500 RUB that used to be hard-coded in the payment request.
+INSERT INTO tariffs (name, base_fare) VALUES ('Стандарт', 50000);
+
+-- price is the fare for the first hour and price_per_hour the rate for every
+-- following hour, both fixed when the order is dispatched.
+ALTER TABLE orders
+    ADD COLUMN tariff_id      BIGINT references tariffs (id),
+    ADD COLUMN price          BIGINT,
+    ADD COLUMN price_per_hour BIGINT;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE orders
+    DROP COLUMN tariff_id,
+    DROP COLUMN price,
+    DROP COLUMN price_per_hour;
+
+DROP TABLE tariffs;
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE orders
+    ADD COLUMN source_latitude       double precision,
+    ADD COLUMN source_longitude      double precision,
+    ADD COLUMN destination_latitude  double precision,
+    ADD COLUMN destination_longitude double precision;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE orders
+    DROP COLUMN source_latitude,
+    DROP COLUMN source_longitude,
+    DROP COLUMN destination_latitude,
+    DROP COLUMN destination_longitude;
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE ratings
+(
+    order_id           BIGINT PRIMARY KEY references orders (id),
+    driver_telegram_id BIGINT      NOT NULL,
+    score              SMALLINT    NOT NULL CHECK (score BETWEEN 1 AND 5),
+    comment            TEXT,
+    created_at         timestamptz NOT NULL DEFAULT now()
+);
+CREATE INDEX ON ratings (driver_telegram_id);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE ratings;
+-- +goose StatementEnd
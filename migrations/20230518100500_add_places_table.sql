@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE places
+(
+    id         BIGSERIAL PRIMARY KEY,
+    user_id    UUID        NOT NULL references users (id),
+    -- name is NULL while the user has not named the place yet.
+    name       TEXT,
+    address    TEXT        NOT NULL,
+    latitude   double precision,
+    longitude  double precision,
+    created_at timestamptz NOT NULL DEFAULT now()
+);
+CREATE UNIQUE INDEX ON places (user_id, name);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE places;
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Customers pay for orders and late cancellations, drivers pay the service
+-- commission of the orders they finished.
+ALTER TABLE payments
+    ADD COLUMN purpose text NOT NULL DEFAULT 'order'
+        CHECK (purpose IN ('order', 'late_fee', 'commission'));
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE payments
+    DROP COLUMN purpose;
+-- +goose StatementEnd